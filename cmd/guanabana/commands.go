@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// command is a subcommand such as "guanabana sentences".
+// Subcommands take their own flags; the Lemon-style flags only
+// apply when no subcommand is given.
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"coverage":  {usage: "Report rule coverage from generated parser profiles", run: runCoverage},
+	"export":    {usage: "Export the grammar, automaton and tables as JSON", run: runExport},
+	"graph":     {usage: "Draw the LR automaton as Graphviz DOT or Mermaid", run: runGraph},
+	"railroad":  {usage: "Draw SVG syntax diagrams of every nonterminal", run: runRailroad},
+	"sentences": {usage: "Generate sentences from a grammar for fuzzing", run: runSentences},
+	"shortest":  {usage: "Print the shortest string each nonterminal and rule derives", run: runShortest},
+	"template":  {usage: "Write the default parser template for -T", run: runTemplate},
+}
+
+// printCommands lists the subcommands in name order.
+func printCommands() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Commands:")
+	for _, name := range names {
+		fmt.Printf("  %-12s %s\n", name, commands[name].usage)
+	}
+}
+
+// loadGrammar reads, parses and finalizes a grammar file. Diagnostics are
+// printed to stderr; any error diagnostic fails the load.
+func loadGrammar(path string) (*grammar.Grammar, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lex.Tokenize(path, src)
+	if err != nil {
+		return nil, err
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if !grammar.HasErrors(diags) {
+		var more []grammar.Diagnostic
+		more, err = g.Finalize()
+		diags = append(diags, more...)
+	}
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if err != nil {
+		return nil, err
+	} else if grammar.HasErrors(diags) {
+		return nil, fmt.Errorf("%s: grammar has errors", path)
+	}
+	return g, nil
+}
+
+// writeOutput calls write with the named file, or with stdout if name is
+// empty, and reports the first error from writing or closing.
+func writeOutput(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+
+	"github.com/mdhender/guanabana/internal/coverage"
+	"github.com/mdhender/guanabana/internal/sentence"
+)
+
+// runCoverage implements "guanabana coverage". It merges the profiles
+// written by a generated parser and reports rule coverage against the
+// grammar, listing each untested rule with a sentence that would reach it.
+func runCoverage(args []string) error {
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	perRule := fs.Bool("func", false, "Print the hit count of every rule")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana coverage [options] grammar-file profile...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("coverage: expected a grammar file and at least one profile")
+	}
+
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	profile := coverage.NewProfile("")
+	for _, name := range fs.Args()[1:] {
+		fp, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		p, err := coverage.ReadProfile(fp)
+		fp.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := profile.Merge(p); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if name := filepath.Base(fs.Arg(0)); profile.Grammar != "" && profile.Grammar != name {
+		return fmt.Errorf("coverage: profile is for %s, not %s", profile.Grammar, name)
+	}
+	rep, err := coverage.NewReport(g, profile)
+	if err != nil {
+		return err
+	}
+
+	if *perRule {
+		if err := rep.WriteFunc(os.Stdout); err != nil {
+			return err
+		}
+	}
+	fmt.Println(rep.Summary())
+	untested := rep.Untested()
+	if len(untested) == 0 {
+		return nil
+	}
+	gen, err := sentence.New(g, rand.New(rand.NewPCG(1, 0)), sentence.Options{})
+	if err != nil {
+		return err
+	}
+	fmt.Println("untested rules:")
+	for _, r := range untested {
+		fmt.Printf("%s: %s\n", r.Pos, r)
+		if s, ok := gen.Covering(r); ok {
+			fmt.Printf("\texample: %s\n", sentence.Format(s))
+		}
+	}
+	return nil
+}
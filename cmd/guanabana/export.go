@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mdhender/guanabana/export"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// runExport implements "guanabana export". It writes the grammar, its
+// automaton and its parse tables in a machine-readable form, with the
+// states numbered as in the report and the generated parser unless -r is
+// given.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "output format; only json is supported")
+	lrMode := fs.String("lr", "lalr", "LR construction: lalr, ielr or canonical (ielr takes the time and memory of canonical)")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	noResort := fs.Bool("r", false, "do not sort or renumber states")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana export [options] grammar-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("export: expected one grammar file")
+	}
+	if *format != "json" {
+		return fmt.Errorf("export: unknown format %q", *format)
+	}
+	mode, err := lalr.ParseMode(*lrMode)
+	if err != nil {
+		return err
+	}
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	a := lalr.Build(g, mode)
+	if !*noResort {
+		lalr.Resort(a)
+	}
+	doc := export.Build(fs.Arg(0), a)
+
+	return writeOutput(*output, doc.Write)
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/mdhender/guanabana/internal/codegen"
+	"github.com/mdhender/guanabana/internal/counterexample"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/report"
+	"github.com/mdhender/guanabana/internal/sqlscript"
+)
+
+// GenerateParser reads the grammar file, builds the automaton, writes the
+// Go parser to the output directory if it type-checks, and reports the
+// conflicts, each with a counterexample. Unless -q is given it also writes
+// the report file, -S adds a SQL script and -m a token package. If the
+// grammar pins its conflict counts with %expect or %expect_rr, a matching
+// count is silent and any other count is an error that writes nothing but
+// the report.
+func (p Parser) GenerateParser(grammarFile string) error {
+	mode, err := lalr.ParseMode(p.LRMode)
+	if err != nil {
+		return err
+	}
+	g, err := loadGrammar(grammarFile)
+	if err != nil {
+		return err
+	}
+	a := lalr.Build(g, mode)
+	if !p.NoResort {
+		lalr.Resort(a)
+	}
+	conflicts := lalr.DetectAndResolveConflicts(a, g)
+	table := lalr.BuildTables(a, g)
+	var packed *lalr.CompressedTable
+	if !p.NoCompress {
+		packed = lalr.Compress(table)
+	}
+	if p.Stats {
+		printStats(os.Stdout, a, conflicts, table, packed)
+	}
+	if !p.Quiet {
+		if err := p.writeReport(grammarFile, a); err != nil {
+			return err
+		}
+	}
+	// A grammar whose conflict counts do not match its %expect fails here,
+	// with only the report written to explain the conflicts.
+	sr, rr, pinned := g.ExpectedConflicts()
+	if pinned && (sr != conflicts.ShiftReduce() || rr != conflicts.ReduceReduce()) {
+		reportConflicts(os.Stderr, grammarFile, a, conflicts, mode)
+		return fmt.Errorf("%s: expected %d shift/reduce and %d reduce/reduce conflicts, found %d and %d",
+			grammarFile, sr, rr, conflicts.ShiftReduce(), conflicts.ReduceReduce())
+	}
+	if p.SQL {
+		err := p.writeOutputFile(p.outputName(grammarFile, ".sql"), func(w io.Writer) error {
+			return sqlscript.Write(w, grammarFile, a)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// Generate and check before creating the file, so an error leaves no
+	// partial or broken parser.
+	var src bytes.Buffer
+	output := p.outputName(grammarFile, ".go")
+	cfg := codegen.Config{
+		Package:     p.packageName(),
+		GrammarFile: filepath.Base(grammarFile),
+		NoCompress:  p.NoCompress,
+		Coverage:    p.Coverage,
+		Trace:       p.Trace,
+		CST:         p.CST,
+		NoLines:     p.NoLineNos,
+		GrammarPath: p.lineFileName(grammarFile),
+		OutputFile:  filepath.Base(output),
+	}
+	if cfg.Template, err = p.loadTemplate(); err != nil {
+		return err
+	}
+	if err := codegen.Generate(&src, a, cfg); err != nil {
+		return err
+	}
+	diags, err := codegen.Check(a, cfg, filepath.Dir(output))
+	if err != nil {
+		return err
+	}
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if len(diags) > 0 {
+		return fmt.Errorf("%s: generated parser has type errors", grammarFile)
+	}
+	err = p.writeOutputFile(output, func(w io.Writer) error {
+		_, err := src.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if p.MakeHeaders {
+		if err := p.writeTokens(grammarFile, a); err != nil {
+			return err
+		}
+	}
+	if !pinned {
+		reportConflicts(os.Stderr, grammarFile, a, conflicts, mode)
+	}
+	return nil
+}
+
+// writeReport writes the Lemon-style report file, by default next to the
+// generated parser in the output directory.
+func (p Parser) writeReport(grammarFile string, a *lalr.Automaton) error {
+	name := p.ReportFilename
+	if name == "" {
+		name = p.outputName(grammarFile, ".out")
+	}
+	return p.writeOutputFile(name, func(w io.Writer) error {
+		return report.Write(w, a, report.Options{
+			Basis:           p.Basisflag,
+			Precedence:      p.ShowPrecedence,
+			Counterexamples: true,
+		})
+	})
+}
+
+// writeTokens writes the -m token file, by default to the tokens
+// directory of the output directory. Its package is named after its
+// directory, which must not be the parser's.
+func (p Parser) writeTokens(grammarFile string, a *lalr.Automaton) error {
+	name := p.HeaderFilename
+	if name == "" {
+		name = filepath.Join(p.Outdir, "tokens", filepath.Base(p.outputName(grammarFile, ".go")))
+	}
+	dir, err := filepath.Abs(filepath.Dir(name))
+	if err != nil {
+		return err
+	}
+	if outdir, err := filepath.Abs(p.Outdir); err == nil && outdir == dir {
+		return fmt.Errorf("%s: the token file must be outside the parser's directory", name)
+	}
+	pkg := filepath.Base(dir)
+	if !token.IsIdentifier(pkg) {
+		pkg = "tokens"
+	}
+	var src bytes.Buffer
+	err = codegen.GenerateTokens(&src, a, codegen.Config{Package: pkg, GrammarFile: filepath.Base(grammarFile)})
+	if err != nil {
+		return err
+	}
+	return p.writeOutputFile(name, func(w io.Writer) error {
+		_, err := src.WriteTo(w)
+		return err
+	})
+}
+
+// loadTemplate returns the parser template given by TemplateContent or,
+// if that is empty, read from the -T file, or nil for the default.
+func (p Parser) loadTemplate() (*template.Template, error) {
+	if p.TemplateContent == "" && p.TemplateFile != "" {
+		src, err := os.ReadFile(p.TemplateFile)
+		if err != nil {
+			return nil, err
+		}
+		p.TemplateFilename, p.TemplateContent = p.TemplateFile, string(src)
+	}
+	if p.TemplateContent == "" {
+		return nil, nil
+	}
+	name := p.TemplateFilename
+	if name == "" {
+		name = "template"
+	}
+	return codegen.ParseTemplate(name, p.TemplateContent)
+}
+
+// packageName returns the package name of the generated parser: -package,
+// or else the name of the output directory if it is a valid identifier.
+func (p Parser) packageName() string {
+	if p.Package != "" {
+		return p.Package
+	}
+	dir, err := filepath.Abs(p.Outdir)
+	if err != nil {
+		return "parser"
+	}
+	if name := filepath.Base(dir); token.IsIdentifier(name) {
+		return name
+	}
+	return "parser"
+}
+
+// lineFileName returns the name of the grammar file for //line directives
+// in the generated parser: its path relative to the output directory.
+func (p Parser) lineFileName(grammarFile string) string {
+	if rel, err := filepath.Rel(p.Outdir, grammarFile); err == nil {
+		return rel
+	}
+	return grammarFile
+}
+
+// outputName returns the name of an output file in the output directory:
+// the grammar file's base name with its extension replaced by ext.
+func (p Parser) outputName(grammarFile, ext string) string {
+	base := filepath.Base(grammarFile)
+	return filepath.Join(p.Outdir, strings.TrimSuffix(base, filepath.Ext(base))+ext)
+}
+
+// writeOutputFile creates the file's directory if needed and writes it.
+func (p Parser) writeOutputFile(name string, write func(w io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return writeOutput(name, write)
+}
+
+// reportConflicts lists the unresolved conflicts, each with a
+// counterexample, followed by those caused by LALR state merging.
+func reportConflicts(w io.Writer, grammarFile string, a *lalr.Automaton, conflicts *lalr.ConflictReport, mode lalr.Mode) {
+	g := a.Grammar
+	for _, c := range conflicts.Conflicts {
+		if c.Resolved {
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", grammarFile, c.String(g))
+		if ex, err := counterexample.Find(a, c); err == nil {
+			ex.Write(w, g)
+		}
+	}
+	if mode == lalr.ModeLALR && conflicts.Unresolved > 0 {
+		if merged := lalr.MergeConflicts(a, g); len(merged) > 0 {
+			fmt.Fprintf(w, "%s: %d conflict(s) caused by LALR state merging (--lr=ielr avoids them):\n", grammarFile, len(merged))
+			for _, c := range merged {
+				fmt.Fprintf(w, "\t%s\n", c.String(g))
+			}
+		}
+	}
+	if conflicts.Unresolved > 0 {
+		fmt.Fprintf(w, "%d parsing conflicts.\n", conflicts.Unresolved)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mdhender/guanabana/internal/graph"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// runGraph implements "guanabana graph". It draws the LR automaton as a
+// Graphviz DOT or Mermaid graph, with the states numbered as in the report
+// and the generated parser unless -r is given.
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := fs.String("format", "dot", "output format: dot or mermaid")
+	lrMode := fs.String("lr", "lalr", "LR construction: lalr, ielr or canonical (ielr takes the time and memory of canonical)")
+	from := fs.Int("from", -1, "only show states reachable from this state")
+	conflicts := fs.Bool("conflicts", false, "highlight states with unresolved conflicts")
+	labels := fs.Bool("labels", false, "label edges with the shifted symbol")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	noResort := fs.Bool("r", false, "do not sort or renumber states")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana graph [options] grammar-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("graph: expected one grammar file")
+	}
+	var write func(io.Writer, *lalr.Automaton, graph.Options) error
+	switch *format {
+	case "dot":
+		write = graph.WriteDOT
+	case "mermaid":
+		write = graph.WriteMermaid
+	default:
+		return fmt.Errorf("graph: unknown format %q", *format)
+	}
+	mode, err := lalr.ParseMode(*lrMode)
+	if err != nil {
+		return err
+	}
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	a := lalr.Build(g, mode)
+	if !*noResort {
+		lalr.Resort(a)
+	}
+	opts := graph.Options{From: *from, Conflicts: *conflicts, Labels: *labels}
+	return writeOutput(*output, func(w io.Writer) error {
+		return write(w, a, opts)
+	})
+}
@@ -18,6 +18,17 @@ var (
 )
 
 func main() {
+	// Subcommands are dispatched before the Lemon-style flags are parsed.
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Parse command-line flags similar to the original lemon tool.
 	// For reference only.
 	var (
@@ -25,25 +36,30 @@ func main() {
 		baseFlagPtr       = flag.Bool("b", false, "Show only the basis in report")
 		noCompressFlagPtr = flag.Bool("c", false, "Don't compress the action table")
 		outputDirPtr      = flag.String("d", "", "Output directory")
+		packagePtr        = flag.String("package", "", "Package name of the generated parser (default: the output directory's name)")
 		showHelpPtr       = flag.Bool("?", false, "Show help")
 		showVersionPtr    = flag.Bool("x", false, "Show version")
 		statsFlagPtr      = flag.Bool("s", false, "Show statistics about table generation")
-		templateFilePtr   = flag.String("T", "", "Specify a template file")
+		templateFilePtr   = flag.String("T", "", "Generate the parser with a custom text/template file")
 
 		// Advanced options
 		definePtr          = flag.String("D", "", "Define an %ifdef macro")
-		makeheadersPtr     = flag.Bool("m", false, "Output a makeheaders compatible file")
-		noLineNosPtr       = flag.Bool("l", false, "Do not print #line statements")
+		makeheadersPtr     = flag.Bool("m", false, "Write the token codes to a package of their own")
+		headerFilePtr      = flag.String("mfile", "", "File written by -m (default: <outdir>/tokens/<grammar>.go)")
+		noLineNosPtr       = flag.Bool("l", false, "Do not print //line directives in the generated parser")
 		printGrammarPtr    = flag.Bool("g", false, "Print grammar without actions")
 		printPreprocessPtr = flag.Bool("E", false, "Print input file after preprocessing")
 		quietPtr           = flag.Bool("q", false, "Don't print the report file")
 		noResortPtr        = flag.Bool("r", false, "Do not sort or renumber states")
 		showPrecedencePtr  = flag.Bool("p", false, "Show precedence levels in the report")
 		sqlPtr             = flag.Bool("S", false, "Generate an SQLite3 table of parser statistics")
+		lrModePtr          = flag.String("lr", "lalr", "LR construction: lalr, ielr or canonical (ielr takes the time and memory of canonical)")
+		coverPtr           = flag.Bool("cover", false, "Count rule reductions and state entries in the generated parser")
+		cstPtr             = flag.Bool("cst", false, "Generate a parser that builds a concrete syntax tree, ignoring the grammar's code")
 
 		// Debug options
 		debugPtr = flag.Bool("debug", false, "Enable debug output during parser generation")
-		tracePtr = flag.Bool("trace", false, "Enable trace output in the generated parser")
+		tracePtr = flag.Bool("trace", false, "Add SetTrace to the generated parser to log its steps")
 	)
 
 	flag.Parse()
@@ -51,6 +67,7 @@ func main() {
 	if *showHelpPtr {
 		fmt.Println("Guanabana LALR(1) Parser Generator")
 		flag.PrintDefaults()
+		printCommands()
 		return
 	}
 
@@ -64,6 +81,7 @@ func main() {
 	if len(args) < 1 {
 		fmt.Println("Error: No grammar file specified")
 		fmt.Println("Usage: guanabana [options] grammar-file")
+		fmt.Println("       guanabana command [options] grammar-file")
 		os.Exit(1)
 	}
 
@@ -74,7 +92,7 @@ func main() {
 
 	// Basic options
 	p.Basisflag = *baseFlagPtr
-	p.NoResort = *noCompressFlagPtr
+	p.NoCompress = *noCompressFlagPtr
 	p.Stats = *statsFlagPtr
 	p.TemplateFile = *templateFilePtr
 
@@ -84,6 +102,7 @@ func main() {
 	} else {
 		p.Outdir = *outputDirPtr
 	}
+	p.Package = *packagePtr
 
 	// Advanced options
 	if *definePtr != "" {
@@ -93,6 +112,7 @@ func main() {
 		fmt.Printf("Warning: -D option not fully implemented yet\n")
 	}
 	p.MakeHeaders = *makeheadersPtr
+	p.HeaderFilename = *headerFilePtr
 	p.NoLineNos = *noLineNosPtr
 	p.PrintGrammar = *printGrammarPtr
 	p.PrintPreprocess = *printPreprocessPtr
@@ -100,6 +120,9 @@ func main() {
 	p.NoResort = *noResortPtr
 	p.ShowPrecedence = *showPrecedencePtr
 	p.SQL = *sqlPtr
+	p.LRMode = *lrModePtr
+	p.Coverage = *coverPtr
+	p.CST = *cstPtr
 
 	// Debug options
 	p.Debug = *debugPtr
@@ -111,14 +134,13 @@ func main() {
 	}
 }
 
-// Parser is a copy of the original lemon parser generator. It's not used; it
-// is here for reference purposes. We'll replace it with the Guanabana Parser.
-// It contains the entire state of the parser generator, including the grammar,
-// configuration options, and the parser state machine. This is the main type
-// used to parse grammar files and generate parser code.
+// Parser started as a copy of the original lemon parser generator's state.
+// It holds the configuration options of a generator run; the commented-out
+// fields are kept for reference until the Guanabana equivalents replace them.
 type Parser struct {
 	// Parser configuration
 	Basisflag      bool   // Output only basis configurations
+	NoCompress     bool   // Do not compress the action table
 	NoResort       bool   // Do not sort or renumber states
 	ShowPrecedence bool   // Show precedence conflicts in the report
 	Quiet          bool   // Don't print non-essential information
@@ -127,14 +149,18 @@ type Parser struct {
 	StartRule      string // Name of the start rule
 	IncludePath    string // Directory for inclusion preprocessor
 	Outdir         string // Directory where files are written
+	Package        string // Package name of the generated parser
 	TemplateFile   string // Template file
 
 	// Advanced options
-	MakeHeaders     bool // Output a makeheaders compatible file
-	NoLineNos       bool // Do not print #line statements
-	PrintGrammar    bool // Print grammar without actions
-	PrintPreprocess bool // Print input file after preprocessing
-	SQL             bool // Generate an SQLite3 table of parser statistics
+	MakeHeaders     bool   // Write the token codes to a package of their own
+	NoLineNos       bool   // Do not print //line directives
+	PrintGrammar    bool   // Print grammar without actions
+	PrintPreprocess bool   // Print input file after preprocessing
+	SQL             bool   // Generate an SQLite3 table of parser statistics
+	LRMode          string // LR construction: "lalr", "ielr" or "canonical"
+	Coverage        bool   // Count rule reductions and state entries in the generated parser
+	CST             bool   // Build a concrete syntax tree instead of running the grammar's code
 
 	// Debug options
 	Debug bool // Enable debug output during parser generation
@@ -175,7 +201,3 @@ type Parser struct {
 	HeaderFilename   string // The header file name
 	ReportFilename   string // The report file name
 }
-
-func (p Parser) GenerateParser(grammarFile string) error {
-	panic("not implemented")
-}
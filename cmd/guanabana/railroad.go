@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mdhender/guanabana/internal/railroad"
+)
+
+// runRailroad implements "guanabana railroad". It writes one SVG syntax
+// diagram per nonterminal and an HTML index that shows them all.
+func runRailroad(args []string) error {
+	fs := flag.NewFlagSet("railroad", flag.ExitOnError)
+	outdir := fs.String("d", "railroad", "output directory")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana railroad [options] grammar-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("railroad: expected one grammar file")
+	}
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outdir, 0o755); err != nil {
+		return err
+	}
+	diagrams := railroad.Build(g)
+	for _, d := range diagrams {
+		if err := writeOutput(filepath.Join(*outdir, d.Name+".svg"), d.WriteSVG); err != nil {
+			return err
+		}
+	}
+	title := filepath.Base(fs.Arg(0))
+	return writeOutput(filepath.Join(*outdir, "index.html"), func(w io.Writer) error {
+		return railroad.WriteIndex(w, title, diagrams)
+	})
+}
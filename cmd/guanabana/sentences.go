@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/sentence"
+)
+
+// runSentences implements "guanabana sentences". Each sentence is written
+// on its own line as space-separated terminal names.
+func runSentences(args []string) error {
+	fs := flag.NewFlagSet("sentences", flag.ExitOnError)
+	count := fs.Int("n", 10, "Number of random sentences to generate")
+	depth := fs.Int("depth", sentence.DefaultMaxDepth, "Derivation depth after which the shallowest rules are used")
+	seed := fs.Uint64("seed", 1, "Seed for the random number generator")
+	exhaustive := fs.Bool("exhaustive", false, "Enumerate every sentence up to -maxlen terminals")
+	maxLen := fs.Int("maxlen", 4, "Maximum sentence length for -exhaustive")
+	cover := fs.Bool("cover", false, "Generate one sentence per reachable rule")
+	weights := map[int]float64{}
+	fs.Func("weight", "Rule weight as `rule=weight` (repeatable)", func(s string) error {
+		idx, w, ok := strings.Cut(s, "=")
+		if !ok {
+			return errors.New("expected rule=weight")
+		}
+		n, err := strconv.Atoi(idx)
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(w, 64)
+		if err != nil {
+			return err
+		}
+		weights[n] = f
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana sentences [options] grammar-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("sentences: expected one grammar file")
+	}
+
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var list [][]*grammar.Symbol
+	if *exhaustive {
+		list, err = sentence.Enumerate(g, *maxLen)
+		if err != nil {
+			return err
+		}
+	} else {
+		gen, err := sentence.New(g, rand.New(rand.NewPCG(*seed, 0)), sentence.Options{MaxDepth: *depth, Weights: weights})
+		if err != nil {
+			return err
+		}
+		if *cover {
+			list = gen.Cover()
+		} else {
+			for i := 0; i < *count; i++ {
+				list = append(list, gen.Sentence())
+			}
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	for _, s := range list {
+		fmt.Fprintln(w, sentence.Format(s))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/sentence"
+)
+
+// runShortest implements "guanabana shortest". It prints the shortest
+// terminal string derived by every nonterminal and every rule.
+func runShortest(args []string) error {
+	fs := flag.NewFlagSet("shortest", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana shortest grammar-file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("shortest: expected one grammar file")
+	}
+	g, err := loadGrammar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	sh := analysis.ComputeShortest(g)
+
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintln(w, "nonterminals:")
+	for _, nt := range g.Symbols.Nonterminals() {
+		if nt == g.Accept {
+			continue
+		}
+		if str, ok := sh.Symbols[nt.ID]; ok {
+			fmt.Fprintf(w, "  %s: %s\n", nt.Name, quoteSentence(sentence.Format(str)))
+		} else {
+			fmt.Fprintf(w, "  %s: (unproductive)\n", nt.Name)
+		}
+	}
+	fmt.Fprintln(w, "rules:")
+	for _, r := range g.Rules {
+		if r == g.AcceptRule {
+			continue
+		}
+		if str, ok := sh.Rules[r.Index]; ok {
+			fmt.Fprintf(w, "  %s: %s => %s\n", r.Pos, r, quoteSentence(sentence.Format(str)))
+		} else {
+			fmt.Fprintf(w, "  %s: %s => (unproductive)\n", r.Pos, r)
+		}
+	}
+	return w.Flush()
+}
+
+// quoteSentence makes the empty sentence visible.
+func quoteSentence(s string) string {
+	if s == "" {
+		return "ε"
+	}
+	return s
+}
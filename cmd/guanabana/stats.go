@@ -0,0 +1,40 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// printStats prints Lemon's -s summary of the grammar and its tables.
+// packed is nil when -c turned compression off.
+func printStats(w io.Writer, a *lalr.Automaton, conflicts *lalr.ConflictReport, table *lalr.ParseTable, packed *lalr.CompressedTable) {
+	g := a.Grammar
+	line := func(label string, n int) {
+		fmt.Fprintf(w, "  %-28s %6d\n", label, n)
+	}
+	fmt.Fprintln(w, "Parser statistics:")
+	line("terminal symbols", table.NumTerminals)
+	line("nonterminal symbols", table.NumNonterminals)
+	line("total symbols", table.NumTerminals+table.NumNonterminals)
+	line("rules", len(g.Rules))
+	line("states", table.NumStates)
+	line("conflicts", conflicts.Unresolved)
+	line("table size (uncompressed)", table.Size())
+	if packed == nil {
+		fmt.Fprintln(w, "  compression disabled (-c)")
+		return
+	}
+	defaults := 0
+	for _, act := range packed.Default {
+		if act.Kind == lalr.ActionReduce {
+			defaults++
+		}
+	}
+	line("default reductions", defaults)
+	line("packed entries", len(packed.Entries))
+	line("table size (compressed)", packed.Size())
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mdhender/guanabana/internal/codegen"
+)
+
+// runTemplate implements "guanabana template dump". It writes the default
+// parser template, a starting point for a custom template given with -T.
+func runTemplate(args []string) error {
+	fs := flag.NewFlagSet("template", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: guanabana template dump [options]")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "dump" {
+		fs.Usage()
+		return errors.New("template: expected dump")
+	}
+	fs.Parse(args[1:])
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("template: unexpected arguments")
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		_, err := io.WriteString(w, codegen.DefaultTemplate)
+		return err
+	})
+}
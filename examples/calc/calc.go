@@ -0,0 +1,357 @@
+// Code generated by guanabana from calc.y. DO NOT EDIT.
+
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Token codes for Feed and Parse. The end of the input is token 0.
+const (
+	PLUS   = 1
+	MINUS  = 2
+	TIMES  = 3
+	DIVIDE = 4
+	UMINUS = 5
+	LPAREN = 6
+	RPAREN = 7
+	NUMBER = 8
+)
+
+// Token is a token from the lexer: its code and its value.
+type Token struct {
+	Type  int
+	Value float64
+}
+
+// SyntaxError is the error for a token that the parser cannot accept.
+type SyntaxError struct {
+	Token    string   // name of the token
+	Pos      int      // number of the token in the input, from 1
+	Expected []string // names of the tokens that the parser could accept instead
+}
+
+// Error returns e.g. "syntax error near RPAREN, expected one of: NUMBER, LPAREN".
+func (e *SyntaxError) Error() string {
+	msg := "syntax error near " + e.Token
+	switch len(e.Expected) {
+	case 0:
+	case 1:
+		msg += ", expected " + e.Expected[0]
+	default:
+		msg += ", expected one of: " + strings.Join(e.Expected, ", ")
+	}
+	return msg
+}
+
+// Parser is a push parser: the lexer drives it by passing each token to
+// Feed (or Parse), then calls End at the end of the input and Result for
+// the value of the start symbol.
+type Parser struct {
+	stack  []yyStackEntry
+	result float64
+	done   bool
+	err    error
+	pos    int // number of the current token in the input, from 1
+
+	// The stack as it was when the current token arrived, before the
+	// reductions on it, for the expected tokens of a syntax error: the
+	// entries below base are unchanged, and popped holds the states of
+	// those above it, top first.
+	base   int
+	popped []int
+}
+
+// yyStackEntry is one entry of the parser stack.
+type yyStackEntry struct {
+	state int     // state entered
+	major int     // symbol ID
+	minor yyMinor // semantic value
+}
+
+// yyMinor holds the value of a symbol in the field for its type.
+type yyMinor struct {
+	yy0 float64
+}
+
+// NewParser returns a parser ready for the first token.
+func NewParser() *Parser {
+	p := &Parser{}
+	p.stack = make([]yyStackEntry, 1, 100)
+	return p
+}
+
+// Reset makes the parser ready for a new input, keeping its stack's
+// memory.
+func (p *Parser) Reset() {
+	p.popAll()
+	*p = Parser{stack: p.stack}
+}
+
+// Feed passes the next token to the parser.
+func (p *Parser) Feed(tok Token) error {
+	return p.Parse(tok.Type, tok.Value)
+}
+
+// End tells the parser that the input is complete. It is the same as
+// passing token 0, and does nothing if the input was already accepted.
+func (p *Parser) End() error {
+	if p.done && p.err == nil {
+		return nil
+	}
+	var minor float64
+	return p.Parse(0, minor)
+}
+
+// FeedAll passes every token of a sequence to the parser, ends the input
+// and returns the result. The sequence may include the end-of-input token.
+// It stops at the first error.
+func (p *Parser) FeedAll(tokens iter.Seq[Token]) (float64, error) {
+	for tok := range tokens {
+		if err := p.Feed(tok); err != nil {
+			return p.result, err
+		} else if p.done {
+			break
+		}
+	}
+	if err := p.End(); err != nil {
+		return p.result, err
+	}
+	return p.Result()
+}
+
+// Parse passes the next token's code and value to the parser; token 0
+// ends the input. After a syntax error, Parse returns the same error for
+// every further token until Reset.
+func (p *Parser) Parse(major int, minor float64) error {
+	if p.err != nil {
+		return p.err
+	} else if p.done {
+		return errors.New("token after end of input")
+	} else if major < 0 || major >= yyNumTerminals {
+		p.err = fmt.Errorf("invalid token %d", major)
+		return p.err
+	}
+	p.pos++
+	p.base, p.popped = len(p.stack), p.popped[:0]
+	for {
+		act := yyFindAction(p.stack[len(p.stack)-1].state, major)
+		switch act & 3 {
+		case yyShift:
+			p.push(act>>2, major, yyMinor{yy0: minor})
+			return nil
+		case yyReduce:
+			p.reduce(act >> 2)
+		case yyAccept:
+			p.result = p.stack[len(p.stack)-1].minor.yy0
+			p.done = true
+			// The result is the caller's now, not a value to destroy.
+			clear(p.stack[1:])
+			p.stack = p.stack[:1]
+			return nil
+		default:
+			err := p.errorAt(major)
+			return p.fail(err)
+		}
+	}
+}
+
+// errorAt returns the syntax error for a token.
+func (p *Parser) errorAt(major int) *SyntaxError {
+	return &SyntaxError{Token: yySymbolName[major], Pos: p.pos, Expected: p.expected()}
+}
+
+// expected returns the names of the terminals that the parser could have
+// accepted instead of the current token. Each is tried on the stack as it
+// was when the token arrived, through any reductions, so a default
+// reduction made on the token hides nothing.
+func (p *Parser) expected() []string {
+	stack := make([]int, 0, p.base+len(p.popped))
+	for _, e := range p.stack[:p.base] {
+		stack = append(stack, e.state)
+	}
+	for i := len(p.popped) - 1; i >= 0; i-- {
+		stack = append(stack, p.popped[i])
+	}
+	var names []string
+	for major := range yyNumTerminals {
+		if yyAccepts(stack, major) {
+			names = append(names, yySymbolName[major])
+		}
+	}
+	return names
+}
+
+// yyAccepts reports whether a stack of states shifts or accepts a terminal
+// after the reductions it makes on it. The stack is left unchanged: the
+// states that reductions push are kept apart.
+func yyAccepts(stack []int, major int) bool {
+	n := len(stack)
+	var pushed []int
+	top := func() int {
+		if len(pushed) > 0 {
+			return pushed[len(pushed)-1]
+		}
+		return stack[n-1]
+	}
+	for {
+		act := yyFindAction(top(), major)
+		switch act & 3 {
+		case yyShift, yyAccept:
+			return true
+		case yyReduce:
+			rule := act >> 2
+			size := int(yyRuleSize[rule])
+			k := min(size, len(pushed))
+			pushed, n = pushed[:len(pushed)-k], n-(size-k)
+			pushed = append(pushed, yyFindGoto(top(), int(yyRuleLHS[rule])))
+		default:
+			return false
+		}
+	}
+}
+
+// fail ends the parse with an error. It empties the stack.
+func (p *Parser) fail(err error) error {
+	p.err = err
+	p.popAll()
+	return err
+}
+
+// popAll empties the stack down to its bottom entry.
+func (p *Parser) popAll() {
+	clear(p.stack[1:])
+	p.stack = p.stack[:1]
+}
+
+// Result returns the value of the start symbol once the end of the input
+// has been accepted.
+func (p *Parser) Result() (float64, error) {
+	if p.err != nil {
+		return p.result, p.err
+	} else if !p.done {
+		return p.result, errors.New("parse incomplete: end of input not seen")
+	}
+	return p.result, nil
+}
+
+// push enters a state.
+func (p *Parser) push(state, major int, minor yyMinor) {
+	p.stack = append(p.stack, yyStackEntry{state: state, major: major, minor: minor})
+}
+
+// reduce pops the right-hand side of a rule, runs its action and pushes
+// the left-hand side. A rule whose LHS has no alias passes on the value of
+// its first RHS symbol, if that has the same type.
+func (p *Parser) reduce(rule int) {
+	n := len(p.stack) - int(yyRuleSize[rule])
+	for ; p.base > n; p.base-- {
+		p.popped = append(p.popped, p.stack[p.base-1].state)
+	}
+	yyrhs := p.stack[n:]
+	var yylhs yyMinor
+	switch rule {
+	case 0: // expr ::= expr PLUS expr.
+		/*line calc.y:11:38*/ yylhs.yy0 = yyrhs[0].minor.yy0 + yyrhs[2].minor.yy0
+//line calc.go:260
+	case 1: // expr ::= expr MINUS expr.
+		/*line calc.y:12:38*/ yylhs.yy0 = yyrhs[0].minor.yy0 - yyrhs[2].minor.yy0
+//line calc.go:263
+	case 2: // expr ::= expr TIMES expr.
+		/*line calc.y:13:38*/ yylhs.yy0 = yyrhs[0].minor.yy0 * yyrhs[2].minor.yy0
+//line calc.go:266
+	case 3: // expr ::= expr DIVIDE expr.
+		/*line calc.y:14:38*/ yylhs.yy0 = yyrhs[0].minor.yy0 / yyrhs[2].minor.yy0
+//line calc.go:269
+	case 4: // expr ::= MINUS expr.
+		/*line calc.y:15:38*/ yylhs.yy0 = -yyrhs[1].minor.yy0
+//line calc.go:272
+	case 5: // expr ::= LPAREN expr RPAREN.
+		/*line calc.y:16:38*/ yylhs.yy0 = yyrhs[1].minor.yy0
+//line calc.go:275
+	case 6: // expr ::= NUMBER.
+		yylhs.yy0 = yyrhs[0].minor.yy0
+	}
+	clear(p.stack[n:])
+	p.stack = p.stack[:n]
+	lhs := int(yyRuleLHS[rule])
+	state := yyFindGoto(p.stack[n-1].state, lhs)
+	p.push(state, lhs, yylhs)
+}
+
+// Actions are encoded with the kind in the low two bits and the target
+// state or rule index above them.
+const (
+	yyError  = 0
+	yyShift  = 1
+	yyReduce = 2
+	yyAccept = 3
+
+	yyNumTerminals = 9
+)
+
+// yyFindAction returns the action for a lookahead terminal. The ACTION and
+// GOTO rows share yyEntry; yyCheck tells which row an entry belongs to,
+// and a lookahead with no entry takes the state's default action.
+func yyFindAction(state, major int) int {
+	if i := int(yyActionBase[state]) + major; i >= 0 && i < len(yyCheck) && int(yyCheck[i]) == major {
+		return int(yyEntry[i])
+	}
+	return int(yyDefault[state])
+}
+
+// yyFindGoto returns the state entered after reducing to a nonterminal.
+func yyFindGoto(state, lhs int) int {
+	if i := int(yyGotoBase[state]) + lhs; i >= 0 && i < len(yyCheck) && int(yyCheck[i]) == lhs {
+		return int(yyEntry[i]) >> 2
+	}
+	return -1
+}
+
+var yyDefault = [...]int8{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 6, 26, 18, 22, 10, 14,
+}
+
+var yyActionBase = [...]int8{
+	7, 0, 4, 7, 7, 7, 7, 7, 7, 13, 13, -11, -11, -11, -11, -11,
+}
+
+var yyGotoBase = [...]int8{
+	1, -11, -11, 3, 5, 9, 10, 11, 12, -11, -11, -11, -11, -11, -11, -11,
+}
+
+var yyEntry = [...]int8{
+	3, 21, 25, 29, 33, 21, 25, 29, 33, 13, 5, 53, 49, 17, 9, 45,
+	29, 33, 37, 41, 57, 61,
+}
+
+var yyCheck = [...]int8{
+	0, 1, 2, 3, 4, 1, 2, 3, 4, 2, 9, 7, 9, 6, 9, 8,
+	3, 4, 9, 9, 9, 9,
+}
+
+var yyRuleLHS = [...]int8{
+	9, 9, 9, 9, 9, 9, 9, 10,
+}
+
+var yyRuleSize = [...]int8{
+	3, 3, 3, 3, 2, 3, 1, 1,
+}
+
+// yySymbolName names every symbol, by ID.
+var yySymbolName = [...]string{
+	"$",
+	"PLUS",
+	"MINUS",
+	"TIMES",
+	"DIVIDE",
+	"UMINUS",
+	"LPAREN",
+	"RPAREN",
+	"NUMBER",
+	"expr",
+	"$accept",
+}
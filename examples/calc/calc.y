@@ -0,0 +1,17 @@
+// Calculator grammar for the generated-parser examples and tests.
+// The hand-written lexer in lexer.go drives the parser generated from
+// this file into calc.go.
+
+%token_type {float64}
+
+%left PLUS MINUS.
+%left TIMES DIVIDE.
+%right UMINUS.
+
+expr(A) ::= expr(B) PLUS expr(C).   { A = B + C }
+expr(A) ::= expr(B) MINUS expr(C).  { A = B - C }
+expr(A) ::= expr(B) TIMES expr(C).  { A = B * C }
+expr(A) ::= expr(B) DIVIDE expr(C). { A = B / C }
+expr(A) ::= MINUS expr(B). [UMINUS] { A = -B }
+expr(A) ::= LPAREN expr(B) RPAREN.  { A = B }
+expr ::= NUMBER.
@@ -0,0 +1,161 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package calc
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/codegen"
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestEval(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want float64
+	}{
+		{"1", 1},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"8 - 2 - 1", 5},
+		{"8 / 2 / 2", 2},
+		{"-2 * -3", 6},
+		{"-(1.5 + 2.5)", -4},
+	} {
+		got, err := Eval(tc.src)
+		if err != nil {
+			t.Errorf("%q: %v", tc.src, err)
+		} else if got != tc.want {
+			t.Errorf("%q = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"1 +", "syntax error near $, expected one of: MINUS, LPAREN, NUMBER"},
+		{"1 2", "syntax error near NUMBER, expected one of: $, PLUS, MINUS, TIMES, DIVIDE"},
+		{"(1", "syntax error near $, expected one of: PLUS, MINUS, TIMES, DIVIDE, RPAREN"},
+		{")", "syntax error near RPAREN, expected one of: MINUS, LPAREN, NUMBER"},
+		{"", "syntax error near $, expected one of: MINUS, LPAREN, NUMBER"},
+		{"1 # 2", `3: unexpected '#'`},
+	} {
+		_, err := Eval(tc.src)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%q: error %v, want %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+// TestFeed drives the parser one token at a time, the way a lexer loop
+// would, and then reuses it.
+func TestFeed(t *testing.T) {
+	p := NewParser()
+	for range 2 {
+		l := NewLexer("2 * (3 + 4)")
+		for tok := l.Next(); tok.Type != 0; tok = l.Next() {
+			if err := p.Feed(tok); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := p.Result(); err == nil {
+			t.Error("Result before End succeeded")
+		}
+		if err := p.End(); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.End(); err != nil {
+			t.Errorf("second End: %v", err)
+		}
+		if v, err := p.Result(); err != nil || v != 14 {
+			t.Errorf("Result = %v, %v; want 14", v, err)
+		}
+		if err := p.Feed(Token{Type: NUMBER, Value: 1}); err == nil {
+			t.Error("Feed after the end of the input succeeded")
+		}
+		p.Reset()
+	}
+}
+
+// TestParse uses the lower-level Parse with token codes and values.
+func TestParse(t *testing.T) {
+	p := NewParser()
+	for _, tok := range []Token{{Type: NUMBER, Value: 6}, {Type: DIVIDE}, {Type: NUMBER, Value: 4}, {}} {
+		if err := p.Parse(tok.Type, tok.Value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, err := p.Result(); err != nil || v != 1.5 {
+		t.Errorf("Result = %v, %v; want 1.5", v, err)
+	}
+	if err := NewParser().Parse(99, 0); err == nil {
+		t.Error("Parse accepted an invalid token code")
+	}
+}
+
+// TestResetAfterError checks that an error sticks until Reset.
+func TestResetAfterError(t *testing.T) {
+	p := NewParser()
+	if err := p.Feed(Token{Type: RPAREN}); err == nil {
+		t.Fatal("no syntax error")
+	}
+	if err := p.Feed(Token{Type: NUMBER, Value: 1}); err == nil {
+		t.Error("error did not stick")
+	}
+	p.Reset()
+	if v, err := p.FeedAll(NewLexer("1 + 1").Tokens()); err != nil || v != 2 {
+		t.Errorf("after Reset: %v, %v; want 2", v, err)
+	}
+}
+
+// TestFeedAll feeds sequences with and without the end-of-input token.
+func TestFeedAll(t *testing.T) {
+	toks := []Token{{Type: NUMBER, Value: 2}, {Type: TIMES}, {Type: NUMBER, Value: 21}}
+	for _, seq := range [][]Token{toks, append(toks, Token{})} {
+		if v, err := NewParser().FeedAll(slices.Values(seq)); err != nil || v != 42 {
+			t.Errorf("FeedAll(%v) = %v, %v; want 42", seq, v, err)
+		}
+	}
+}
+
+// TestParserIsUpToDate regenerates the parser and compares it with the
+// checked-in calc.go; run "go generate" after changing calc.y or the
+// code generator.
+func TestParserIsUpToDate(t *testing.T) {
+	src, err := os.ReadFile("calc.y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := lex.Tokenize("calc.y", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	a := lalr.Build(g, lalr.ModeLALR)
+	lalr.Resort(a)
+	var want bytes.Buffer
+	if err := codegen.Generate(&want, a, codegen.Config{Package: "calc", GrammarFile: "calc.y"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("calc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Error("calc.go is out of date; run go generate")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package calc is a four-function calculator whose hand-written lexer
+// drives a parser generated from calc.y.
+package calc
+
+//go:generate go run ../../cmd/guanabana -q -d . -package calc calc.y
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// Lexer splits a calculator expression into tokens.
+type Lexer struct {
+	src string
+	pos int
+	err error
+}
+
+// NewLexer returns a lexer for the source text.
+func NewLexer(src string) *Lexer {
+	return &Lexer{src: src}
+}
+
+// Next returns the next token. At the end of the input, or after an
+// error, it returns token 0.
+func (l *Lexer) Next() Token {
+	for l.err == nil && l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		switch ch {
+		case ' ', '\t', '\n', '\r':
+			l.pos++
+			continue
+		case '+', '-', '*', '/', '(', ')':
+			l.pos++
+			return Token{Type: operators[ch]}
+		}
+		if isDigit(ch) || ch == '.' {
+			return l.number()
+		}
+		l.err = fmt.Errorf("%d: unexpected %q", l.pos+1, ch)
+	}
+	return Token{}
+}
+
+var operators = map[byte]int{
+	'+': PLUS,
+	'-': MINUS,
+	'*': TIMES,
+	'/': DIVIDE,
+	'(': LPAREN,
+	')': RPAREN,
+}
+
+// number scans a decimal number.
+func (l *Lexer) number() Token {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	v, err := strconv.ParseFloat(l.src[start:l.pos], 64)
+	if err != nil {
+		l.err = fmt.Errorf("%d: invalid number %q", start+1, l.src[start:l.pos])
+		return Token{}
+	}
+	return Token{Type: NUMBER, Value: v}
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// Tokens returns the tokens of the input, ending with token 0.
+func (l *Lexer) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			tok := l.Next()
+			if !yield(tok) || tok.Type == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the error that stopped the lexer, if any.
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// Eval returns the value of an expression.
+func Eval(src string) (float64, error) {
+	l := NewLexer(src)
+	v, err := NewParser().FeedAll(l.Tokens())
+	if l.Err() != nil {
+		return 0, l.Err()
+	}
+	return v, err
+}
@@ -1,9 +1,22 @@
 // Sample calculator grammar demonstrating Lemon special features
 // This grammar shows epsilon productions, fallback tokens, and wildcard tokens
 
-%token_type    {float64}   // Define the type of all terminals
+%token_type    {Value}     // Define the type of all terminals
 %default_type  {float64}   // Define the default type for non-terminals
 
+%include {
+import (
+	"fmt"
+	"math"
+)
+
+// Value is the value the lexer passes with every token.
+type Value struct {
+	Text  string
+	Value float64
+}
+}
+
 %left PLUS MINUS.
 %left TIMES DIVIDE.
 %right POW.
@@ -22,31 +35,34 @@ stmt_list ::= stmt_list stmt.
 stmt_list ::= .
 
 // Statement types
-stmt ::= expr SEMI.          { printf("Result: %f\n", $expr); }
-stmt ::= VAR ID ASSIGN expr SEMI.  { setVariable($ID, $expr); }
-stmt ::= PRINT expr SEMI.    { printf("%f\n", $expr); }
-
-expr(A) ::= expr(B) PLUS expr(C).   { A = B + C; }
-expr(A) ::= expr(B) MINUS expr(C).  { A = B - C; }
-expr(A) ::= expr(B) TIMES expr(C).  { A = B * C; }
-expr(A) ::= expr(B) DIVIDE expr(C). { A = B / C; }
-expr(A) ::= expr(B) POW expr(C).    { A = pow(B, C); }
-expr(A) ::= LPAREN expr(B) RPAREN.  { A = B; }
-expr(A) ::= NUMBER(B).              { A = B; }
-expr(A) ::= ID(B).                 { A = getVariable(B); }
+stmt ::= expr(E) SEMI.                { fmt.Printf("Result: %f\n", E) }
+stmt ::= VAR ID(N) ASSIGN expr(E) SEMI. { setVariable(N.Text, E) }
+stmt ::= PRINT expr(E) SEMI.          { fmt.Printf("%f\n", E) }
+
+expr(A) ::= expr(B) PLUS expr(C).   { A = B + C }
+expr(A) ::= expr(B) MINUS expr(C).  { A = B - C }
+expr(A) ::= expr(B) TIMES expr(C).  { A = B * C }
+expr(A) ::= expr(B) DIVIDE expr(C). { A = B / C }
+expr(A) ::= expr(B) POW expr(C).    { A = math.Pow(B, C) }
+expr(A) ::= LPAREN expr(B) RPAREN.  { A = B }
+expr(A) ::= NUMBER(B).              { A = B.Value }
+expr(A) ::= ID(B).                  { A = getVariable(B.Text) }
 
 // Any unrecognized input token will match this rule due to the wildcard
-stmt ::= ERROR. { printf("Syntax error, unexpected token\n"); }
+stmt ::= ERROR. { fmt.Printf("Syntax error, unexpected token\n") }
 
 %code {
 // These functions would be implemented in the actual application
 // They're just placeholders here for the example
-float64 getVariable(char* name) {
-  // Implementation to retrieve variable value
-  return 0.0;
+var variables = map[string]float64{}
+
+func getVariable(name string) float64 {
+	// Implementation to retrieve variable value
+	return variables[name]
 }
 
-void setVariable(char* name, float64 value) {
-  // Implementation to set variable value
+func setVariable(name string, value float64) {
+	// Implementation to set variable value
+	variables[name] = value
+}
 }
-}
\ No newline at end of file
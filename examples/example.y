@@ -4,17 +4,16 @@
 %left PLUS MINUS.
 %left TIMES DIVIDE.
 
-%syntax_error {printf("Syntax error!\n");}
+%syntax_error {fmt.Printf("Syntax error!\n")}
 
 %include {
-#include <stdio.h>
-#include <stdlib.h>
+import "fmt"
 }
 
-program ::= expr(A). { printf("Result: %d\n", A); }
+program ::= expr(A). { fmt.Printf("Result: %d\n", A) }
 
-expr(A) ::= expr(B) PLUS expr(C).   { A = B + C; }
-expr(A) ::= expr(B) MINUS expr(C).  { A = B - C; }
-expr(A) ::= expr(B) TIMES expr(C).  { A = B * C; }
-expr(A) ::= expr(B) DIVIDE expr(C). { A = B / C; }
-expr(A) ::= INTEGER(B).             { A = B; }
\ No newline at end of file
+expr(A) ::= expr(B) PLUS expr(C).   { A = B + C }
+expr(A) ::= expr(B) MINUS expr(C).  { A = B - C }
+expr(A) ::= expr(B) TIMES expr(C).  { A = B * C }
+expr(A) ::= expr(B) DIVIDE expr(C). { A = B / C }
+expr(A) ::= INTEGER(B).             { A = B }
\ No newline at end of file
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package export
+
+import (
+	"sort"
+
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// Build exports an automaton with lookaheads, its grammar and its tables.
+// grammarFile is recorded as the document's grammar name.
+func Build(grammarFile string, a *lalr.Automaton) *Document {
+	g := a.Grammar
+	d := &Document{
+		Schema:    Schema,
+		Version:   Version,
+		Grammar:   grammarFile,
+		Mode:      a.Mode.String(),
+		Symbols:   []Symbol{},
+		Rules:     []Rule{},
+		States:    []State{},
+		Conflicts: []Conflict{},
+	}
+
+	nullable := analysis.ComputeNullable(g)
+	first := analysis.ComputeFirst(g, nullable)
+	follow := analysis.ComputeFollow(g, nullable, first)
+	for _, sym := range g.Symbols.All() {
+		s := Symbol{
+			ID:         sym.ID,
+			Name:       sym.Name,
+			Kind:       sym.Kind.String(),
+			Alias:      sym.Alias,
+			Precedence: sym.Precedence.Level,
+			Pos:        position(sym.Pos),
+		}
+		if !sym.Precedence.IsZero() {
+			s.Assoc = sym.Precedence.Assoc.String()
+		}
+		if !sym.IsTerminal() {
+			s.Nullable = nullable[sym.ID]
+			s.First = ids(first[sym.ID])
+			s.Follow = ids(follow[sym.ID])
+		}
+		d.Symbols = append(d.Symbols, s)
+	}
+
+	for _, r := range g.Rules {
+		rhs := make([]int, len(r.RHS))
+		for i, sym := range r.RHS {
+			rhs[i] = sym.ID
+		}
+		d.Rules = append(d.Rules, Rule{
+			Index:      r.Index,
+			LHS:        r.LHS.ID,
+			RHS:        rhs,
+			Text:       r.String(),
+			Precedence: r.Precedence.Level,
+			Pos:        position(r.Pos),
+			ActionPos:  position(r.ActionPos),
+		})
+	}
+
+	table := lalr.BuildTables(a, g)
+	for _, st := range a.States {
+		s := State{ID: st.ID, Symbol: -1, Items: []Item{}, Actions: []Action{}, Gotos: []Goto{}}
+		if st.Symbol != nil {
+			s.Symbol = st.Symbol.ID
+		}
+		for _, item := range st.LAItems {
+			s.Items = append(s.Items, Item{
+				Rule:      item.RuleIndex,
+				Dot:       item.Dot,
+				Kernel:    item.IsKernel(g),
+				Lookahead: ids(item.Lookahead),
+			})
+		}
+		for t, act := range table.Action[st.ID] {
+			if act.Kind != lalr.ActionError {
+				s.Actions = append(s.Actions, Action{Terminal: t, Kind: act.Kind.String(), State: act.State, Rule: act.RuleIndex})
+			}
+		}
+		for _, nt := range g.Symbols.Nonterminals() {
+			if to := table.GotoState(st.ID, nt); to >= 0 {
+				s.Gotos = append(s.Gotos, Goto{Nonterminal: nt.ID, State: to})
+			}
+		}
+		d.States = append(d.States, s)
+	}
+
+	for _, c := range lalr.DetectAndResolveConflicts(a, g).Conflicts {
+		rules := []int{c.ReduceItem.RuleIndex}
+		if c.ReduceItem2 != nil {
+			rules = append(rules, c.ReduceItem2.RuleIndex)
+		}
+		d.Conflicts = append(d.Conflicts, Conflict{
+			State:      c.StateID,
+			Terminal:   c.Terminal.ID,
+			Kind:       c.Kind.String(),
+			Rules:      rules,
+			Resolution: c.Resolution,
+			Resolved:   c.Resolved,
+		})
+	}
+	return d
+}
+
+// position converts a grammar position; the zero position becomes nil.
+func position(p lex.Position) *Position {
+	if p.IsZero() {
+		return nil
+	}
+	return &Position{File: p.File, Line: p.Line, Column: p.Column}
+}
+
+// ids returns the members of a set in increasing order.
+func ids(set map[int]bool) []int {
+	var list []int
+	for id, ok := range set {
+		if ok {
+			list = append(list, id)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
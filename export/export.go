@@ -0,0 +1,153 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package export defines the JSON form of a grammar, its LR automaton and
+// its parse tables, as written by "guanabana export --format=json".
+//
+// The document is versioned. Version 1 has these fields; symbols, rules and
+// states are listed by ID and refer to each other by ID:
+//
+//	schema     always "guanabana-export"
+//	version    the schema version, currently 1
+//	grammar    the grammar file name
+//	mode       the LR construction: "lalr", "ielr" or "canonical"
+//	symbols    every symbol: id, name, kind ("terminal" or "nonterminal"),
+//	           alias, precedence, assoc and pos, and for nonterminals
+//	           nullable, first and follow (terminal IDs)
+//	rules      every rule: index, lhs, rhs (symbol IDs), text, precedence,
+//	           pos and actionPos; the augmented start rule comes last
+//	states     every state: id, symbol (the symbol shifted to enter it, or
+//	           -1 for state 0), items (rule, dot, kernel and, for reduce
+//	           items, lookahead), actions (the ACTION row) and gotos (the
+//	           GOTO row)
+//	conflicts  every conflict: state, terminal, kind, rules, resolution
+//	           and resolved
+//
+// An action has a terminal and a kind of "shift", "reduce" or "accept";
+// a shift names its target state and a reduce its rule, and a missing
+// number means 0. Error cells of the ACTION table are left out, as are
+// GOTO cells with no target.
+// Positions are {file, line, column} objects and are omitted when unknown.
+//
+// Later versions only add fields, so a reader of version N accepts any
+// document with a version from 1 to N.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// Schema names the document type.
+	Schema = "guanabana-export"
+	// Version is the schema version this package reads and writes.
+	Version = 1
+)
+
+// Document is an exported grammar with its automaton and tables.
+type Document struct {
+	Schema    string     `json:"schema"`
+	Version   int        `json:"version"`
+	Grammar   string     `json:"grammar"`
+	Mode      string     `json:"mode"`
+	Symbols   []Symbol   `json:"symbols"`
+	Rules     []Rule     `json:"rules"`
+	States    []State    `json:"states"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Position is a location in the grammar file.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Symbol is a terminal or nonterminal.
+type Symbol struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Kind       string    `json:"kind"`
+	Alias      string    `json:"alias,omitempty"`
+	Precedence int       `json:"precedence,omitempty"`
+	Assoc      string    `json:"assoc,omitempty"`
+	Pos        *Position `json:"pos,omitempty"`
+	Nullable   bool      `json:"nullable,omitempty"`
+	First      []int     `json:"first,omitempty"`
+	Follow     []int     `json:"follow,omitempty"`
+}
+
+// Rule is a grammar rule.
+type Rule struct {
+	Index      int       `json:"index"`
+	LHS        int       `json:"lhs"`
+	RHS        []int     `json:"rhs"`
+	Text       string    `json:"text"`
+	Precedence int       `json:"precedence,omitempty"`
+	Pos        *Position `json:"pos,omitempty"`
+	ActionPos  *Position `json:"actionPos,omitempty"`
+}
+
+// State is a state of the LR automaton with its rows of the parse tables.
+type State struct {
+	ID      int      `json:"id"`
+	Symbol  int      `json:"symbol"`
+	Items   []Item   `json:"items"`
+	Actions []Action `json:"actions"`
+	Gotos   []Goto   `json:"gotos"`
+}
+
+// Item is an LR item; only reduce items have a lookahead set.
+type Item struct {
+	Rule      int   `json:"rule"`
+	Dot       int   `json:"dot"`
+	Kernel    bool  `json:"kernel"`
+	Lookahead []int `json:"lookahead,omitempty"`
+}
+
+// Action is an entry of the ACTION table.
+type Action struct {
+	Terminal int    `json:"terminal"`
+	Kind     string `json:"kind"`
+	State    int    `json:"state,omitempty"` // for shift: the target state
+	Rule     int    `json:"rule,omitempty"`  // for reduce: the rule index
+}
+
+// Goto is an entry of the GOTO table.
+type Goto struct {
+	Nonterminal int `json:"nonterminal"`
+	State       int `json:"state"`
+}
+
+// Conflict is a conflict met while building the ACTION table.
+type Conflict struct {
+	State      int    `json:"state"`
+	Terminal   int    `json:"terminal"`
+	Kind       string `json:"kind"`
+	Rules      []int  `json:"rules"`
+	Resolution string `json:"resolution"`
+	Resolved   bool   `json:"resolved"`
+}
+
+// Write encodes the document as indented JSON.
+func (d *Document) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// Read decodes a document and checks its schema name and version.
+func Read(r io.Reader) (*Document, error) {
+	var d Document
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+	if d.Schema != Schema {
+		return nil, fmt.Errorf("export: schema %q, want %q", d.Schema, Schema)
+	}
+	if d.Version < 1 || d.Version > Version {
+		return nil, fmt.Errorf("export: unsupported version %d (this reader handles 1 to %d)", d.Version, Version)
+	}
+	return &d, nil
+}
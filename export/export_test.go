@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+const arith = `
+%left PLUS.
+expr ::= expr PLUS term.
+expr ::= term.
+term ::= NUM.
+`
+
+func build(t *testing.T, src string) *Document {
+	t.Helper()
+	tokens, err := lex.Tokenize("arith.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return Build("arith.y", lalr.BuildLALR(g))
+}
+
+func symbolID(t *testing.T, d *Document, name string) int {
+	t.Helper()
+	for _, s := range d.Symbols {
+		if s.Name == name {
+			return s.ID
+		}
+	}
+	t.Fatalf("symbol %q not found", name)
+	return -1
+}
+
+func TestBuild(t *testing.T) {
+	d := build(t, arith)
+	if d.Schema != Schema || d.Version != Version || d.Mode != "lalr" || d.Grammar != "arith.y" {
+		t.Errorf("header = %q %d %q %q", d.Schema, d.Version, d.Mode, d.Grammar)
+	}
+	plus, num := symbolID(t, d, "PLUS"), symbolID(t, d, "NUM")
+	expr := d.Symbols[symbolID(t, d, "expr")]
+	if !reflect.DeepEqual(expr.First, []int{num}) || !reflect.DeepEqual(expr.Follow, []int{0, plus}) {
+		t.Errorf("expr FIRST = %v, FOLLOW = %v", expr.First, expr.Follow)
+	}
+	if p := d.Symbols[plus]; p.Precedence != 1 || p.Assoc != "left" || p.Pos == nil || p.Pos.Line != 2 {
+		t.Errorf("PLUS = %+v", p)
+	}
+	r := d.Rules[0]
+	if r.Text != "expr ::= expr PLUS term." || r.Pos == nil || r.Pos.Line != 3 || r.ActionPos != nil {
+		t.Errorf("rule 0 = %+v", r)
+	}
+	if len(d.States) != 6 || d.States[0].Symbol != -1 || d.States[1].Symbol != num {
+		t.Fatalf("states = %+v", d.States)
+	}
+	want := []Action{{Terminal: 0, Kind: "reduce", Rule: 2}, {Terminal: plus, Kind: "reduce", Rule: 2}}
+	if got := d.States[1].Actions; !reflect.DeepEqual(got, want) {
+		t.Errorf("state 1 actions = %+v, want %+v", got, want)
+	}
+	if got := d.States[1].Items; len(got) != 1 || !got[0].Kernel || !reflect.DeepEqual(got[0].Lookahead, []int{0, plus}) {
+		t.Errorf("state 1 items = %+v", got)
+	}
+	if got := d.States[0].Gotos; len(got) != 2 || got[0] != (Goto{Nonterminal: expr.ID, State: 2}) {
+		t.Errorf("state 0 gotos = %+v", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	d := build(t, arith)
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, d) {
+		t.Errorf("round trip changed the document:\n%+v\n%+v", back, d)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	d := build(t, `
+stmt ::= IF ID THEN stmt.
+stmt ::= IF ID THEN stmt ELSE stmt.
+stmt ::= OTHER.
+`)
+	if len(d.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v", d.Conflicts)
+	}
+	c := d.Conflicts[0]
+	if c.Kind != "shift/reduce" || c.Terminal != symbolID(t, d, "ELSE") || c.Resolution != "shift" || c.Resolved {
+		t.Errorf("conflict = %+v", c)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for _, tc := range []struct{ src, want string }{
+		{`{`, "unexpected EOF"},
+		{`{"schema":"other","version":1}`, `schema "other"`},
+		{`{"schema":"guanabana-export","version":2}`, "unsupported version 2"},
+		{`{"schema":"guanabana-export"}`, "unsupported version 0"},
+	} {
+		_, err := Read(strings.NewReader(tc.src))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Read(%s) = %v, want an error containing %q", tc.src, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func names(str []*grammar.Symbol) string {
+	var list []string
+	for _, s := range str {
+		list = append(list, s.Name)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestShortest(t *testing.T) {
+	g := mustGrammar(t, `
+program ::= stmt_list.
+stmt_list ::= stmt_list stmt.
+stmt_list ::= .
+stmt ::= PRINT expr SEMI.
+stmt ::= expr SEMI.
+expr ::= expr PLUS expr.
+expr ::= LPAREN expr RPAREN.
+expr ::= NUM.
+loop ::= loop X.
+`)
+	sh := ComputeShortest(g)
+	for name, want := range map[string]string{
+		"program":   "",
+		"stmt_list": "",
+		"stmt":      "NUM SEMI",
+		"expr":      "NUM",
+		"PLUS":      "PLUS",
+	} {
+		sym, _ := g.Symbols.Lookup(name)
+		got, ok := sh.Symbols[sym.ID]
+		if !ok {
+			t.Errorf("%s: no shortest string", name)
+		} else if names(got) != want {
+			t.Errorf("%s: shortest = %q, want %q", name, names(got), want)
+		}
+	}
+	for idx, want := range map[int]string{
+		1: "NUM SEMI",
+		3: "PRINT NUM SEMI",
+		5: "NUM PLUS NUM",
+		6: "LPAREN NUM RPAREN",
+	} {
+		if got := names(sh.Rules[idx]); got != want {
+			t.Errorf("rule %d: shortest = %q, want %q", idx, got, want)
+		}
+	}
+	loop, _ := g.Symbols.Lookup("loop")
+	if _, ok := sh.Symbols[loop.ID]; ok {
+		t.Error("unproductive loop should have no shortest string")
+	}
+	if _, ok := sh.Rules[8]; ok {
+		t.Error("unproductive rule should have no shortest string")
+	}
+}
+
+func TestShortestEpsilonCycle(t *testing.T) {
+	g := mustGrammar(t, `
+a ::= b.
+a ::= X.
+b ::= a.
+b ::= .
+`)
+	sh := ComputeShortest(g)
+	a, _ := g.Symbols.Lookup("a")
+	if got := sh.Symbols[a.ID]; len(got) != 0 {
+		t.Errorf("a: shortest = %q, want empty", names(got))
+	}
+}
+
+// buildGrammar builds and finalizes a grammar from a list of rules, each
+// written as "LHS rhs...". Symbols that never appear as an LHS are terminals.
+func buildGrammar(t *testing.T, rules ...string) *grammar.Grammar {
+	t.Helper()
+	g := grammar.NewGrammar()
+	lhs := map[string]bool{}
+	for _, r := range rules {
+		lhs[strings.Fields(r)[0]] = true
+	}
+	for _, r := range rules {
+		for _, name := range strings.Fields(r) {
+			if lhs[name] {
+				g.AddNonterminal(name)
+			} else {
+				g.AddTerminal(name)
+			}
+		}
+	}
+	for _, r := range rules {
+		f := strings.Fields(r)
+		if _, err := g.AddRule(f[0], f[1:], ""); err != nil {
+			t.Fatalf("AddRule: %v", err)
+		}
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+// grammarA: expr → expr PLUS term | term; term → NUM
+func grammarA(t *testing.T) *grammar.Grammar {
+	return buildGrammar(t, "expr expr PLUS term", "expr term", "term NUM")
+}
+
+// grammarB: list → list item | ε; item → WORD
+func grammarB(t *testing.T) *grammar.Grammar {
+	return buildGrammar(t, "list list item", "list", "item WORD")
+}
+
+// grammarC: S → A B c; A → a | ε; B → b | ε
+func grammarC(t *testing.T) *grammar.Grammar {
+	return buildGrammar(t, "S A B c", "A a", "A", "B b", "B")
+}
+
+func lookup(t *testing.T, g *grammar.Grammar, name string) *grammar.Symbol {
+	t.Helper()
+	sym, ok := g.Symbols.Lookup(name)
+	if !ok {
+		t.Fatalf("symbol %q not found", name)
+	}
+	return sym
+}
+
+// setNames renders a set of symbol IDs as space-separated names in ID order.
+// In grammar C the terminal c is declared first, so it sorts before a and b.
+func setNames(g *grammar.Grammar, set map[int]bool) string {
+	var list []string
+	for _, s := range g.Symbols.All() {
+		if set[s.ID] {
+			list = append(list, s.Name)
+		}
+	}
+	return strings.Join(list, " ")
+}
+
+func TestNullableNone(t *testing.T) {
+	g := grammarA(t)
+	nullable := ComputeNullable(g)
+	for _, nt := range g.Symbols.Nonterminals() {
+		if nullable[nt.ID] {
+			t.Errorf("%s should not be nullable", nt.Name)
+		}
+	}
+}
+
+func TestNullableEpsilon(t *testing.T) {
+	g := grammarB(t)
+	nullable := ComputeNullable(g)
+	if !nullable[lookup(t, g, "list").ID] {
+		t.Error("list should be nullable")
+	}
+	if nullable[lookup(t, g, "item").ID] {
+		t.Error("item should not be nullable")
+	}
+}
+
+func TestNullableChained(t *testing.T) {
+	g := grammarC(t)
+	nullable := ComputeNullable(g)
+	for name, want := range map[string]bool{"A": true, "B": true, "S": false, "c": false} {
+		if got := nullable[lookup(t, g, name).ID]; got != want {
+			t.Errorf("nullable(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFirst(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		g     *grammar.Grammar
+		first map[string]string
+	}{
+		{"A", grammarA(t), map[string]string{"expr": "NUM", "term": "NUM", "PLUS": "PLUS"}},
+		{"B", grammarB(t), map[string]string{"list": "WORD", "item": "WORD"}},
+		{"C", grammarC(t), map[string]string{"S": "c a b", "A": "a", "B": "b"}},
+	} {
+		nullable := ComputeNullable(tc.g)
+		first := ComputeFirst(tc.g, nullable)
+		for name, want := range tc.first {
+			if got := setNames(tc.g, first[lookup(t, tc.g, name).ID]); got != want {
+				t.Errorf("grammar %s: FIRST(%s) = {%s}, want {%s}", tc.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestFirstOfSequence(t *testing.T) {
+	g := grammarC(t)
+	nullable := ComputeNullable(g)
+	first := ComputeFirst(g, nullable)
+	a, b, c := lookup(t, g, "A"), lookup(t, g, "B"), lookup(t, g, "c")
+	for _, tc := range []struct {
+		seq  []*grammar.Symbol
+		want string
+	}{
+		{[]*grammar.Symbol{a, b}, "a b"},
+		{[]*grammar.Symbol{b, c, a}, "c b"},
+		{[]*grammar.Symbol{c, a}, "c"},
+		{nil, ""},
+	} {
+		if got := setNames(g, FirstOfSequence(tc.seq, first, nullable)); got != tc.want {
+			t.Errorf("FIRST(%s) = {%s}, want {%s}", names(tc.seq), got, tc.want)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		g      *grammar.Grammar
+		follow map[string]string
+	}{
+		{"A", grammarA(t), map[string]string{"expr": "$ PLUS", "term": "$ PLUS"}},
+		{"B", grammarB(t), map[string]string{"list": "$ WORD", "item": "$ WORD"}},
+		{"C", grammarC(t), map[string]string{"S": "$", "A": "c b", "B": "c"}},
+	} {
+		nullable := ComputeNullable(tc.g)
+		first := ComputeFirst(tc.g, nullable)
+		follow := ComputeFollow(tc.g, nullable, first)
+		for name, want := range tc.follow {
+			if got := setNames(tc.g, follow[lookup(t, tc.g, name).ID]); got != want {
+				t.Errorf("grammar %s: FOLLOW(%s) = {%s}, want {%s}", tc.name, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package analysis
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// ComputeFirst returns FIRST sets indexed by symbol ID. Each set holds the
+// IDs of the terminals that can begin a string derived from the symbol.
+// For a terminal T, FIRST(T) = {T}.
+//
+// For each rule A ::= X1 X2 ... Xn, FIRST(A) gains FIRST(X1), and FIRST(Xi+1)
+// as long as X1 ... Xi are all nullable. The sets only grow, so the
+// iteration reaches a fixed point.
+func ComputeFirst(g *grammar.Grammar, nullable map[int]bool) map[int]map[int]bool {
+	first := map[int]map[int]bool{}
+	for _, s := range g.Symbols.All() {
+		first[s.ID] = map[int]bool{}
+		if s.Kind == grammar.SymbolTerminal {
+			first[s.ID][s.ID] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			lhs := first[r.LHS.ID]
+			for _, x := range r.RHS {
+				for t := range first[x.ID] {
+					if !lhs[t] {
+						lhs[t] = true
+						changed = true
+					}
+				}
+				if !nullable[x.ID] {
+					break
+				}
+			}
+		}
+	}
+	return first
+}
+
+// FirstOfSequence returns the set of terminal IDs that can begin a string
+// derived from seq. The result is empty if seq is empty.
+func FirstOfSequence(seq []*grammar.Symbol, first map[int]map[int]bool, nullable map[int]bool) map[int]bool {
+	result := map[int]bool{}
+	for _, s := range seq {
+		for t := range first[s.ID] {
+			result[t] = true
+		}
+		if !nullable[s.ID] {
+			break
+		}
+	}
+	return result
+}
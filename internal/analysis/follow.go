@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package analysis
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// ComputeFollow returns FOLLOW sets indexed by nonterminal symbol ID. Each
+// set holds the IDs of the terminals that can appear immediately after the
+// nonterminal in some sentential form. The EOF marker is in FOLLOW(start).
+//
+// For each rule A ::= α B β, FOLLOW(B) gains FIRST(β), and FOLLOW(A) too
+// when β is nullable. The sets only grow, so the iteration terminates.
+func ComputeFollow(g *grammar.Grammar, nullable map[int]bool, first map[int]map[int]bool) map[int]map[int]bool {
+	follow := map[int]map[int]bool{}
+	for _, nt := range g.Symbols.Nonterminals() {
+		follow[nt.ID] = map[int]bool{}
+	}
+	if g.Start != nil && g.EOF != nil {
+		follow[g.Start.ID][g.EOF.ID] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			for i, x := range r.RHS {
+				if x.Kind == grammar.SymbolTerminal {
+					continue
+				}
+				beta := r.RHS[i+1:]
+				set := follow[x.ID]
+				for t := range FirstOfSequence(beta, first, nullable) {
+					if !set[t] {
+						set[t] = true
+						changed = true
+					}
+				}
+				if allNullable(beta, nullable) {
+					for t := range follow[r.LHS.ID] {
+						if !set[t] {
+							set[t] = true
+							changed = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return follow
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package analysis
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// ComputeNullable returns the set of symbol IDs that can derive the empty
+// string. Only nonterminals can be nullable.
+//
+// The set is found by fixed-point iteration: a nonterminal is nullable if
+// any of its rules has an RHS made only of nullable symbols (an empty RHS
+// qualifies trivially). The set only grows, so the loop terminates.
+func ComputeNullable(g *grammar.Grammar) map[int]bool {
+	nullable := map[int]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if nullable[r.LHS.ID] {
+				continue
+			}
+			if allNullable(r.RHS, nullable) {
+				nullable[r.LHS.ID] = true
+				changed = true
+			}
+		}
+	}
+	return nullable
+}
+
+// allNullable reports whether every symbol in seq is nullable.
+// The empty sequence is nullable.
+func allNullable(seq []*grammar.Symbol, nullable map[int]bool) bool {
+	for _, s := range seq {
+		if !nullable[s.ID] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package analysis computes properties of a grammar that the table
+// builder and the tools need: nullable symbols, FIRST and FOLLOW sets,
+// and shortest derivations.
+package analysis
+
+import (
+	"math"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// Shortest holds the shortest terminal string derived by each symbol and
+// each rule. Unproductive symbols and rules have no entry.
+type Shortest struct {
+	Symbols map[int][]*grammar.Symbol // by symbol ID
+	Rules   map[int][]*grammar.Symbol // by rule index
+	// Via is the rule that derives the shortest string of each nonterminal.
+	Via map[int]*grammar.Rule
+}
+
+// ComputeShortest returns the shortest string of terminals that every
+// symbol and rule derives.
+//
+// Lengths are found by fixed-point iteration: a terminal has length 1 and
+// a nonterminal the minimum over its rules of the summed lengths of the
+// RHS. Lengths only decrease, so the loop terminates. The rule is recorded
+// only on a strict decrease, which keeps the chosen rules free of cycles
+// even when ε-rules make several choices equally short.
+func ComputeShortest(g *grammar.Grammar) *Shortest {
+	length := map[int]int{}
+	via := map[int]*grammar.Rule{}
+	for _, s := range g.Symbols.All() {
+		if s.Kind == grammar.SymbolTerminal {
+			length[s.ID] = 1
+		} else {
+			length[s.ID] = math.MaxInt
+		}
+	}
+	ruleLength := func(r *grammar.Rule) int {
+		n := 0
+		for _, s := range r.RHS {
+			if length[s.ID] == math.MaxInt {
+				return math.MaxInt
+			}
+			n += length[s.ID]
+		}
+		return n
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if n := ruleLength(r); n < length[r.LHS.ID] {
+				length[r.LHS.ID] = n
+				via[r.LHS.ID] = r
+				changed = true
+			}
+		}
+	}
+
+	sh := &Shortest{Symbols: map[int][]*grammar.Symbol{}, Rules: map[int][]*grammar.Symbol{}, Via: via}
+	var expand func(s *grammar.Symbol) []*grammar.Symbol
+	expand = func(s *grammar.Symbol) []*grammar.Symbol {
+		if str, ok := sh.Symbols[s.ID]; ok {
+			return str
+		}
+		str := []*grammar.Symbol{}
+		if s.Kind == grammar.SymbolTerminal {
+			str = append(str, s)
+		} else {
+			for _, rs := range via[s.ID].RHS {
+				str = append(str, expand(rs)...)
+			}
+		}
+		sh.Symbols[s.ID] = str
+		return str
+	}
+	for _, s := range g.Symbols.All() {
+		if length[s.ID] != math.MaxInt {
+			expand(s)
+		}
+	}
+	for _, r := range g.Rules {
+		if ruleLength(r) == math.MaxInt {
+			continue
+		}
+		str := []*grammar.Symbol{}
+		for _, s := range r.RHS {
+			str = append(str, sh.Symbols[s.ID]...)
+		}
+		sh.Rules[r.Index] = str
+	}
+	return sh
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// substitute returns a rule's action code with every alias replaced by the
+// union field that holds its value: the LHS alias becomes a field of the
+// local yylhs and an RHS alias a field of a popped stack entry in yyrhs.
+// Each alias is thus a variable of its symbol's type.
+// The name of the %extra_argument, if any, becomes the parser's field.
+// Identifiers inside comments and strings and after a "." are left alone.
+func substitute(r *grammar.Rule, types *valueTypes, extra *Extra) (string, error) {
+	repl := map[string]string{}
+	if extra != nil {
+		repl[extra.Name] = "p.extra"
+	}
+	if r.LHSAlias != "" {
+		repl[r.LHSAlias] = "yylhs." + types.field(r.LHS)
+	}
+	for i, alias := range r.RHSAliases {
+		if alias != "" {
+			repl[alias] = fmt.Sprintf("yyrhs[%d].minor.%s", i, types.field(r.RHS[i]))
+		}
+	}
+	return replaceIdents(r.Action, repl)
+}
+
+// hookCode returns the code of a directive such as %syntax_error, which
+// runs in a parser method, with the name of the %extra_argument replaced
+// by the parser's field.
+func hookCode(code string, extra *Extra) (string, error) {
+	repl := map[string]string{}
+	if extra != nil {
+		repl[extra.Name] = "p.extra"
+	}
+	return replaceIdents(code, repl)
+}
+
+// replaceIdents replaces the identifiers of Go code that repl maps,
+// except inside comments and strings and after a ".", and trims the
+// result.
+func replaceIdents(code string, repl map[string]string) (string, error) {
+	src := []byte(code)
+	var errs scanner.ErrorList
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(src))
+	var s scanner.Scanner
+	s.Init(file, src, func(pos token.Position, msg string) { errs.Add(pos, msg) }, 0)
+
+	var sb strings.Builder
+	last, prev := 0, token.ILLEGAL
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.IDENT && prev != token.PERIOD {
+			if expr, ok := repl[lit]; ok {
+				off := file.Offset(pos)
+				sb.Write(src[last:off])
+				sb.WriteString(expr)
+				last = off + len(lit)
+			}
+		}
+		prev = tok
+	}
+	if err := errs.Err(); err != nil {
+		return "", err
+	}
+	sb.Write(src[last:])
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// checkSyntax parses Go code copied from a grammar block whose opening
+// brace is at pos in file, as a function body or, with decls, as top-level
+// declarations. It returns the first syntax error at its place in the
+// grammar file, which the formatter of the generated file cannot give. An
+// error at the end of the body is reported at the block's closing brace.
+func checkSyntax(file string, pos lex.Position, code string, decls bool) error {
+	var sb strings.Builder
+	sb.WriteString("package p\n")
+	if !decls {
+		sb.WriteString("func _() {")
+	}
+	if pos.Line > 0 {
+		fmt.Fprintf(&sb, "/*line %s:%d:%d*/", file, pos.Line, pos.Column+1)
+	}
+	sb.WriteString(code)
+	if !decls {
+		sb.WriteString("\n")
+		if pos.Line > 0 {
+			line, col := positionIn(pos, code, len(code))
+			fmt.Fprintf(&sb, "/*line %s:%d:%d*/", file, line, col)
+		}
+		sb.WriteString("}")
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), file, sb.String(), parser.SkipObjectResolution)
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		return list[0]
+	}
+	return err
+}
+
+// splitImports separates the import declarations at the top of %include
+// code from the rest, so they can be merged with the parser's own. It
+// returns each import spec as source text, e.g. `"strconv"` or
+// `str "strings"`, and the rest with its offset in the code.
+func splitImports(code string) (imports []string, rest string, off int, err error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, "", 0, nil
+	}
+	const header = "package p\n"
+	src := header + code
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	end := len(header)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		for _, spec := range gen.Specs {
+			is := spec.(*ast.ImportSpec)
+			text := is.Path.Value
+			if is.Name != nil {
+				text = is.Name.Name + " " + text
+			}
+			imports = append(imports, text)
+		}
+		end = fset.Position(gen.End()).Offset
+	}
+	off = end - len(header) + leadingSpace(src[end:])
+	return imports, strings.TrimSpace(src[end:]), off, nil
+}
+
+// leadingSpace returns the length of the white space that starts s.
+func leadingSpace(s string) int {
+	return len(s) - len(strings.TrimLeftFunc(s, unicode.IsSpace))
+}
+
+// mergeImports returns the union of two lists of import specs, sorted.
+func mergeImports(a, b []string) []string {
+	all := slices.Concat(a, b)
+	slices.SortFunc(all, func(x, y string) int {
+		if c := strings.Compare(importPath(x), importPath(y)); c != 0 {
+			return c
+		}
+		return strings.Compare(x, y)
+	})
+	return slices.Compact(all)
+}
+
+// importPath returns the quoted path of an import spec.
+func importPath(spec string) string {
+	if i := strings.LastIndexByte(spec, ' '); i >= 0 {
+		return spec[i+1:]
+	}
+	return spec
+}
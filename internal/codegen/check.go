@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// Check type-checks the parser that Generate writes for cfg, together with
+// the other Go files of its package in dir, and returns the type errors as
+// diagnostics. An error in code copied from the grammar is reported at its
+// position in the grammar file; other errors are reported in the generated
+// file. Imports that cannot be loaded are not reported, so only the
+// standard library needs to be available.
+func Check(a *lalr.Automaton, cfg Config, dir string) ([]grammar.Diagnostic, error) {
+	// The //line directives map positions back to the grammar, so check a
+	// parser that has them even if the one written will not.
+	cfg.NoLines = false
+	var src bytes.Buffer
+	if err := Generate(&src, a, cfg); err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	name := filepath.Join(dir, cfg.outputFile())
+	f, err := parser.ParseFile(fset, name, src.Bytes(), parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	files := append(siblings(fset, dir, name, cfg.packageName()), f)
+
+	grammarFile := filepath.Join(dir, newLineDirectives(cfg).file)
+	var diags []grammar.Diagnostic
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error: func(err error) {
+			var terr types.Error
+			if !errors.As(err, &terr) || strings.Contains(terr.Msg, "could not import") {
+				return
+			}
+			pos := terr.Fset.Position(terr.Pos)
+			d := grammar.Diagnostic{
+				Pos:      lex.Position{File: pos.Filename, Line: pos.Line, Column: pos.Column},
+				Severity: grammar.SeverityError,
+				Message:  terr.Msg,
+			}
+			if pos.Filename == grammarFile {
+				d.Pos.File = grammarName(a.Grammar, cfg)
+			}
+			diags = append(diags, d)
+		},
+	}
+	conf.Check(cfg.packageName(), fset, files, nil)
+	return diags, nil
+}
+
+// siblings parses the other non-test Go files of a package in dir. Files
+// that cannot be read or parsed are left out.
+func siblings(fset *token.FileSet, dir, generated, pkg string) []*ast.File {
+	names, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	var files []*ast.File
+	for _, name := range names {
+		if name == generated || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+		if err == nil && f.Name.Name == pkg {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// grammarName returns the grammar file's name as its positions give it.
+func grammarName(g *grammar.Grammar, cfg Config) string {
+	for _, r := range g.Rules {
+		if r.Pos.File != "" {
+			return r.Pos.File
+		}
+	}
+	return cfg.GrammarFile
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nodeGrammar = `
+%token_type {string}
+%type expr {*Node}
+expr(A) ::= ID(B). { A = &Node{Name: B} }
+`
+
+func TestCheck(t *testing.T) {
+	a := buildAutomaton(t, calcGrammar)
+	for _, cfg := range []Config{
+		{Package: "calc", GrammarFile: "calc.y"},
+		{Package: "calc", GrammarFile: "calc.y", NoLines: true},
+	} {
+		diags, err := Check(a, cfg, t.TempDir())
+		if err != nil || len(diags) != 0 {
+			t.Errorf("NoLines=%v: %v %v", cfg.NoLines, diags, err)
+		}
+	}
+}
+
+// TestCheckActionError checks that a type error in an action is reported
+// at its place in the grammar, with or without //line directives.
+func TestCheckActionError(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+expr(A) ::= expr(B) PLUS expr(C). { A = B + C }
+expr(A) ::= ID(B).
+    {
+        A = B + "x"
+    }
+`)
+	for _, noLines := range []bool{false, true} {
+		cfg := Config{Package: "calc", GrammarFile: "calc.y", GrammarPath: "../grammar/calc.y", NoLines: noLines}
+		diags, err := Check(a, cfg, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diags) != 1 || diags[0].Pos.File != "calc.y" || diags[0].Pos.Line != 6 {
+			t.Errorf("NoLines=%v: %v", noLines, diags)
+		}
+	}
+}
+
+// TestCheckSiblings checks that types declared elsewhere in the package
+// are found, and that their absence is reported in the generated file.
+func TestCheckSiblings(t *testing.T) {
+	a := buildAutomaton(t, nodeGrammar)
+	cfg := Config{Package: "ast", GrammarFile: "ast.y"}
+	dir := t.TempDir()
+	diags, err := Check(a, cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) == 0 || diags[0].Pos.File != filepath.Join(dir, "ast.go") || !strings.Contains(diags[0].Message, "undefined: Node") {
+		t.Errorf("without node.go: %v", diags)
+	}
+
+	node := "package ast\n\ntype Node struct{ Name string }\n"
+	if err := os.WriteFile(filepath.Join(dir, "node.go"), []byte(node), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	other := "package other\n\nvar x int = \"x\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "other.go"), []byte(other), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if diags, err := Check(a, cfg, dir); err != nil || len(diags) != 0 {
+		t.Errorf("with node.go: %v %v", diags, err)
+	}
+}
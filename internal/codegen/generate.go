@@ -0,0 +1,588 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package codegen writes a parser as a single, self-contained Go source
+// file: token constants, the parse tables, the shift/reduce loop and the
+// reduce actions, with the aliases in each action replaced by the values
+// they name. The file depends only on the standard library and is
+// formatted with go/format, so the same grammar always produces the same
+// bytes.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// Config selects what Generate writes.
+type Config struct {
+	Package     string // package clause of the generated file
+	GrammarFile string // grammar file name, recorded in the header
+	NoCompress  bool   // write the full ACTION and GOTO tables instead of packed ones
+	Coverage    bool   // count rule reductions and state entries for coverage profiles
+	NoLines     bool   // omit the //line directives that map action code to the grammar
+	Trace       bool   // add SetTrace, which logs the parser's steps
+	CST         bool   // build a concrete syntax tree instead of running the grammar's code
+
+	// Template replaces DefaultTemplate when it is not nil; see
+	// ParseTemplate.
+	Template *template.Template
+
+	// GrammarPath names the grammar file in //line directives; relative
+	// to the generated file's directory, it defaults to GrammarFile.
+	// OutputFile names the generated file in the directives that return
+	// to it; it defaults to GrammarFile with the extension ".go".
+	GrammarPath string
+	OutputFile  string
+}
+
+// DefaultStackSize is the initial capacity of the parser stack when the
+// grammar has no %stack_size.
+const DefaultStackSize = 100
+
+// Generate writes the parser for an automaton with lookaheads.
+func Generate(w io.Writer, a *lalr.Automaton, cfg Config) error {
+	data, err := newData(a, cfg)
+	if err != nil {
+		return err
+	}
+	tmpl := parserTemplate
+	if cfg.Template != nil {
+		tmpl = cfg.Template
+	}
+	src, err := execute(tmpl, data, "parser")
+	if err != nil {
+		return err
+	}
+	if data.Restore != "" {
+		src = restoreLines(src, data.Restore, cfg.outputFile())
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// GenerateTokens writes a file that declares the token codes of the
+// parser that Generate writes, as constants of a type whose String method
+// returns the token's name, in package cfg.Package. A lexer can import it
+// without importing the parser.
+func GenerateTokens(w io.Writer, a *lalr.Automaton, cfg Config) error {
+	cfg.NoLines = true
+	data, err := newData(a, cfg)
+	if err != nil {
+		return err
+	}
+	src, err := execute(tokensTemplate, data, "token file")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// execute runs a template and formats its output, the named file.
+func execute(tmpl *template.Template, data *Data, what string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: generated %s is not valid Go: %w", data.Grammar, what, err)
+	}
+	return src, nil
+}
+
+// packageName returns the package name of the generated file.
+func (cfg Config) packageName() string {
+	if cfg.Package != "" {
+		return cfg.Package
+	}
+	return "parser"
+}
+
+// outputFile returns the name of the generated file for //line directives.
+func (cfg Config) outputFile() string {
+	if cfg.OutputFile != "" {
+		return cfg.OutputFile
+	}
+	return strings.TrimSuffix(cfg.GrammarFile, filepath.Ext(cfg.GrammarFile)) + ".go"
+}
+
+// Data is what the parser template is executed with. Its fields, and
+// those of the types it refers to, are the stable interface for custom
+// templates: they may gain fields but will not lose or change them.
+type Data struct {
+	Grammar   string   // grammar file name
+	Package   string   // package name
+	Parser    string   // name of the parser type
+	Token     string   // name of the token type passed to Feed
+	TokenCode string   // name of the token code type in the token file
+	ErrorType string   // name of the syntax error type
+	Node      string   // name of the syntax tree node type, with CST
+	Leaf      string   // name of the syntax tree token type, with CST
+	Position  string   // name of the source position type, with CST
+	Trivia    string   // name of the trivia type, with CST
+	Imports   []string // import specs, sorted
+	Include   string   // %include code, without its imports
+	Code      string   // %code
+
+	// IncludeComment and CodeComment are the comments that start the
+	// %include and %code blocks; Include and Code hold the rest. With
+	// //line directives, IncludeLine and CodeLine map the rest of the
+	// blocks to the grammar and go between the comment and the code, as
+	// gofmt moves a directive placed before a doc comment below it.
+	// Restore, placed after each block and action, returns to the
+	// generated file. Restore is a placeholder that Generate replaces once
+	// the file is formatted.
+	IncludeComment string
+	CodeComment    string
+	IncludeLine    string
+	CodeLine       string
+	Restore        string
+
+	// Code the parser runs: %syntax_error when it reports a syntax error,
+	// %parse_failure when it cannot recover from one, %parse_accept when
+	// it accepts the input and %stack_overflow when its stack is full.
+	SyntaxError   Block
+	ParseFailure  Block
+	ParseAccept   Block
+	StackOverflow Block
+	ErrorSymbol   int          // ID of the error symbol, or 0 if no rule uses it
+	Destructors   []Destructor // code run for the values the parser discards
+
+	Extra      *Extra // %extra_argument, or nil
+	StackSize  int    // initial capacity of the parser stack
+	StackLimit int    // most entries the stack may hold, or 0 for no limit
+	Coverage   bool   // count reductions and state entries
+	Trace      bool   // log the parser's steps through SetTrace
+	CST        bool   // build a concrete syntax tree; the grammar's code is left out
+
+	Fields    []Field // fields of the value union; the first holds token values
+	TokenType string  // Go type of token values
+	Result    Field   // field and type of the start symbol's value
+
+	Tokens       []Token  // token constants, by ID
+	Symbols      []Symbol // every symbol, by ID: the terminals, then the nonterminals
+	NumTerminals int
+	NumStates    int
+	Mode         string // LR construction of the automaton, as -lr names it
+	Rules        []Rule // every rule, by index, the accept rule last
+	RHSValues    bool   // some rule reads a right-hand side value
+
+	Compressed bool   // Tables holds packed tables rather than full ones
+	Tables     Tables // parse tables
+}
+
+// Token is a token constant.
+type Token struct {
+	Name string
+	ID   int
+}
+
+// Symbol is a grammar symbol.
+type Symbol struct {
+	ID       int
+	Name     string // display name, as in error messages
+	Terminal bool
+	Type     string // Go type of the symbol's value; "" for $accept
+	Field    string // union field that holds the value; "" for $accept
+}
+
+// Field is a field of the value union, which holds the value of one stack
+// entry in the field for its symbol's type.
+type Field struct {
+	Name string
+	Type string
+}
+
+// Block is the code of a directive, with the /*line*/ comment that maps
+// it to the grammar, if any.
+type Block struct {
+	Code string
+	Line string
+}
+
+// Destructor is the code of a %destructor, %token_destructor or
+// %default_destructor for the symbols whose values are in one field, with
+// "$$" replaced by the value.
+type Destructor struct {
+	Symbols []int  // symbol IDs
+	Names   string // names of the symbols, for a comment
+	Code    string
+	Line    string // /*line*/ comment that maps the code to the grammar, or ""
+}
+
+// Extra is the extra argument passed to NewParser and visible in actions.
+type Extra struct {
+	Name string
+	Type string
+}
+
+// Rule is a grammar rule as the reduce code sees it.
+type Rule struct {
+	Index  int
+	Text   string // the rule in grammar notation
+	LHS    int    // symbol ID of the left-hand side
+	NRHS   int    // length of the right-hand side
+	Action string // action code with the aliases substituted, or ""
+	Line   string // /*line*/ comment that maps the action to the grammar, or ""
+	Field  string // union field of the left-hand side value
+	Copy   bool   // the LHS value is the first RHS value: no LHS alias and the same type
+}
+
+// Tables holds the parse tables as Go literals. With compression,
+// Default, ActionBase, GotoBase, Entry and Check are set; without it,
+// Action and Goto are.
+type Tables struct {
+	Type       string // smallest integer type that holds every value
+	Default    string
+	ActionBase string
+	GotoBase   string
+	Entry      string
+	Check      string
+	Action     []string // one row per state
+	Goto       []string // one row per state
+	RuleLHS    string
+	RuleSize   string
+}
+
+// newData collects everything the template needs from the automaton.
+func newData(a *lalr.Automaton, cfg Config) (*Data, error) {
+	g := a.Grammar
+	if cfg.CST {
+		g = withoutCode(g)
+	}
+	d := &Data{
+		Grammar:      cfg.GrammarFile,
+		Package:      cfg.packageName(),
+		Parser:       "Parser",
+		Token:        "Token",
+		TokenCode:    "Code",
+		ErrorType:    "SyntaxError",
+		Node:         "Node",
+		Leaf:         "Leaf",
+		Position:     "Position",
+		Trivia:       "Trivia",
+		StackSize:    g.StackSize(DefaultStackSize),
+		StackLimit:   max(g.StackSizeLimit(), 0),
+		Coverage:     cfg.Coverage,
+		Trace:        cfg.Trace,
+		CST:          cfg.CST,
+		NumTerminals: g.NumTerminals(),
+		NumStates:    len(a.States),
+		Mode:         a.Mode.String(),
+		Compressed:   !cfg.NoCompress,
+	}
+	if name := g.DirectiveValue(grammar.DirName); name != "" {
+		d.Parser = name + "Parser"
+		d.Token = name + "Token"
+		d.TokenCode = name + "Code"
+		d.ErrorType = name + "SyntaxError"
+		d.Node = name + "Node"
+		d.Leaf = name + "Leaf"
+		d.Position = name + "Position"
+		d.Trivia = name + "Trivia"
+	}
+	if d.StackSize < 1 {
+		d.StackSize = DefaultStackSize
+	}
+	if d.StackLimit > 0 {
+		d.StackSize = min(d.StackSize, d.StackLimit)
+	}
+
+	lines := newLineDirectives(cfg)
+	if lines != nil {
+		d.Restore = restoreMarker
+	}
+	file := grammarName(g, cfg)
+	include, _ := g.LastDirective(grammar.DirInclude)
+	if err := checkSyntax(file, include.CodePos, include.Code, true); err != nil {
+		return nil, err
+	}
+	imports, rest, off, err := splitImports(include.Code)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %%include: %w", cfg.GrammarFile, err)
+	}
+	n := leadingComment(rest)
+	d.IncludeComment, d.Include = strings.TrimSpace(rest[:n]), rest[n:]
+	if d.Include != "" {
+		d.IncludeLine = lines.directive(include.CodePos, include.Code, off+n)
+	}
+	code, _ := g.LastDirective(grammar.DirCode)
+	if err := checkSyntax(file, code.CodePos, code.Code, true); err != nil {
+		return nil, err
+	}
+	n = leadingComment(code.Code)
+	d.CodeComment, d.Code = strings.TrimSpace(code.Code[:n]), strings.TrimSpace(code.Code[n:])
+	if d.Code != "" {
+		d.CodeLine = lines.directive(code.CodePos, code.Code, n)
+	}
+	d.Imports = mergeImports(imports, requiredImports(cfg))
+
+	if decl := g.DirectiveValue(grammar.DirExtraArgument); decl != "" {
+		i := strings.IndexFunc(decl, unicode.IsSpace)
+		if i < 0 {
+			return nil, fmt.Errorf("%s: %%extra_argument: want {name Type}, got {%s}", cfg.GrammarFile, decl)
+		}
+		d.Extra = &Extra{Name: decl[:i], Type: strings.TrimSpace(decl[i:])}
+	}
+
+	for _, hook := range []struct {
+		kind  grammar.DirectiveKind
+		block *Block
+	}{
+		{grammar.DirSyntaxError, &d.SyntaxError},
+		{grammar.DirParseFailure, &d.ParseFailure},
+		{grammar.DirParseAccept, &d.ParseAccept},
+		{grammar.DirStackOverflow, &d.StackOverflow},
+	} {
+		b := hook.block
+		dir, _ := g.LastDirective(hook.kind)
+		if strings.TrimSpace(dir.Code) == "" {
+			continue
+		}
+		if err := checkSyntax(file, dir.CodePos, dir.Code, false); err != nil {
+			return nil, err
+		}
+		if b.Code, err = hookCode(dir.Code, d.Extra); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", cfg.GrammarFile, dir.CodePos, err)
+		}
+		b.Line = lines.comment(dir.CodePos, dir.Code, leadingSpace(dir.Code))
+	}
+	if sym, ok := g.Symbols.Lookup(grammar.ErrorName); ok {
+		d.ErrorSymbol = sym.ID
+	}
+
+	prefix := g.DirectiveValue(grammar.DirTokenPrefix)
+	for _, t := range g.Symbols.Terminals() {
+		if t == g.EOF || t.Name == grammar.ErrorName {
+			continue
+		}
+		d.Tokens = append(d.Tokens, Token{Name: prefix + t.Name, ID: t.ID})
+	}
+	types := newTypes(g)
+	if cfg.CST {
+		types = newTreeTypes(g, d.Leaf, "*"+d.Node)
+	}
+	for _, sym := range g.Symbols.All() {
+		s := Symbol{ID: sym.ID, Name: sym.DisplayName(), Terminal: sym.IsTerminal()}
+		if sym != g.Accept {
+			s.Type, s.Field = types.of(sym), types.field(sym)
+		}
+		d.Symbols = append(d.Symbols, s)
+	}
+	d.Fields = types.fields
+	d.TokenType = types.token
+	if d.Destructors, err = newDestructors(g, types, d.Extra, file, lines); err != nil {
+		return nil, err
+	}
+	d.Result = Field{Name: types.field(g.AcceptRule.RHS[0]), Type: types.of(g.AcceptRule.RHS[0])}
+	for _, r := range g.Rules {
+		rule := Rule{
+			Index: r.Index,
+			Text:  r.String(),
+			LHS:   r.LHS.ID,
+			NRHS:  len(r.RHS),
+			Field: types.field(r.LHS),
+			Copy:  r.LHSAlias == "" && len(r.RHS) > 0 && types.field(r.RHS[0]) == types.field(r.LHS),
+		}
+		if r == g.AcceptRule {
+			// Accepted, never reduced.
+			rule.Copy = false
+		}
+		if cfg.CST {
+			// Every reduction builds a node from its right-hand side.
+			rule.Copy = false
+			d.RHSValues = true
+			d.Rules = append(d.Rules, rule)
+			continue
+		}
+		d.RHSValues = d.RHSValues || rule.Copy || slices.ContainsFunc(r.RHSAliases, func(s string) bool { return s != "" })
+		if strings.TrimSpace(r.Action) != "" {
+			if err := checkSyntax(file, r.ActionPos, r.Action, false); err != nil {
+				return nil, err
+			}
+			rule.Action, err = substitute(r, types, d.Extra)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", cfg.GrammarFile, r.ActionPos, err)
+			}
+			rule.Line = lines.comment(r.ActionPos, r.Action, leadingSpace(r.Action))
+		}
+		d.Rules = append(d.Rules, rule)
+	}
+
+	d.Tables = newTables(a, g, cfg.NoCompress)
+	return d, nil
+}
+
+// withoutCode returns a copy of a grammar without the directives that
+// hold Go code or name Go types, for a parser that builds a syntax tree:
+// the code is written for values that such a parser does not have.
+// Rule actions are left out by newData.
+func withoutCode(g *grammar.Grammar) *grammar.Grammar {
+	c := *g
+	c.Directives = slices.DeleteFunc(slices.Clone(g.Directives), func(d grammar.Directive) bool {
+		switch d.Kind {
+		case grammar.DirInclude, grammar.DirCode, grammar.DirExtraArgument, grammar.DirExtraContext,
+			grammar.DirTokenType, grammar.DirType, grammar.DirDefaultType,
+			grammar.DirDestructor, grammar.DirTokenDestructor, grammar.DirDefaultDestructor,
+			grammar.DirSyntaxError, grammar.DirParseAccept, grammar.DirParseFailure, grammar.DirStackOverflow:
+			return true
+		}
+		return false
+	})
+	return &c
+}
+
+// newDestructors collects the destructor of every symbol that has one:
+// %token_destructor for the terminals, and %destructor, or else
+// %default_destructor, for the nonterminals. The end of the input, the
+// error symbol and $accept have no values to destroy. Symbols with the
+// same code and field share a Destructor. Errors are reported in file.
+func newDestructors(g *grammar.Grammar, types *valueTypes, extra *Extra, file string, lines *lineDirectives) ([]Destructor, error) {
+	byName := map[string]grammar.Directive{}
+	for _, d := range g.DirectivesOf(grammar.DirDestructor) {
+		if len(d.Symbols) > 0 {
+			byName[d.Symbols[0]] = d
+		}
+	}
+	tokenDestructor, _ := g.LastDirective(grammar.DirTokenDestructor)
+	defaultDestructor, _ := g.LastDirective(grammar.DirDefaultDestructor)
+
+	type key struct {
+		pos   lex.Position
+		field string
+	}
+	var list []Destructor
+	index := map[key]int{}
+	for _, sym := range g.Symbols.All() {
+		if sym == g.EOF || sym == g.Accept || sym.Name == grammar.ErrorName {
+			continue
+		}
+		dir := tokenDestructor
+		if !sym.IsTerminal() {
+			dir = defaultDestructor
+			if d, ok := byName[sym.Name]; ok {
+				dir = d
+			}
+		}
+		if strings.TrimSpace(dir.Code) == "" {
+			continue
+		}
+		k := key{dir.CodePos, types.field(sym)}
+		if i, ok := index[k]; ok {
+			list[i].Symbols = append(list[i].Symbols, sym.ID)
+			list[i].Names += ", " + sym.Name
+			continue
+		}
+		// yy stands in for $$, keeping the columns of the code after it.
+		if err := checkSyntax(file, dir.CodePos, strings.ReplaceAll(dir.Code, "$$", "yy"), false); err != nil {
+			return nil, err
+		}
+		code := strings.ReplaceAll(dir.Code, "$$", "minor."+k.field)
+		code, err := hookCode(code, extra)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", file, dir.CodePos, err)
+		}
+		index[k] = len(list)
+		list = append(list, Destructor{
+			Symbols: []int{sym.ID},
+			Names:   sym.Name,
+			Code:    code,
+			Line:    lines.comment(dir.CodePos, dir.Code, leadingSpace(dir.Code)),
+		})
+	}
+	return list, nil
+}
+
+// requiredImports returns the packages the generated code itself uses.
+func requiredImports(cfg Config) []string {
+	imports := []string{`"errors"`, `"fmt"`, `"iter"`, `"strings"`}
+	if cfg.Coverage || cfg.Trace {
+		imports = append(imports, `"io"`)
+	}
+	if cfg.Coverage {
+		imports = append(imports, `"sync/atomic"`)
+	}
+	return imports
+}
+
+// valueTypes maps grammar symbols to the Go types of their values and
+// to the fields of the value union that hold them.
+type valueTypes struct {
+	token    string            // %token_type
+	fallback string            // %default_type
+	byName   map[string]string // %type
+	fields   []Field           // union fields, the token type first
+	index    map[string]int    // field index by type
+}
+
+func newTypes(g *grammar.Grammar) *valueTypes {
+	token := g.DirectiveValue(grammar.DirTokenType)
+	if token == "" {
+		token = "any"
+	}
+	fallback := g.DirectiveValue(grammar.DirDefaultType)
+	if fallback == "" {
+		fallback = token
+	}
+	byName := map[string]string{}
+	for _, d := range g.DirectivesOf(grammar.DirType) {
+		if len(d.Symbols) > 0 {
+			byName[d.Symbols[0]] = strings.TrimSpace(d.Code)
+		}
+	}
+	return buildTypes(g, token, fallback, byName)
+}
+
+// newTreeTypes returns the types of a parser that builds a syntax tree:
+// leaf for the terminals and node for the nonterminals.
+func newTreeTypes(g *grammar.Grammar, leaf, node string) *valueTypes {
+	return buildTypes(g, leaf, node, map[string]string{})
+}
+
+// buildTypes gives every type a field of the union, the token type first.
+func buildTypes(g *grammar.Grammar, token, fallback string, byName map[string]string) *valueTypes {
+	t := &valueTypes{token: token, fallback: fallback, byName: byName, index: map[string]int{}}
+	t.add(t.token)
+	for _, nt := range g.Symbols.Nonterminals() {
+		if nt != g.Accept {
+			t.add(t.of(nt))
+		}
+	}
+	return t
+}
+
+// add gives a type a field of the union, unless it has one.
+func (t *valueTypes) add(typ string) {
+	if _, ok := t.index[typ]; !ok {
+		t.index[typ] = len(t.fields)
+		t.fields = append(t.fields, Field{Name: fmt.Sprintf("yy%d", len(t.fields)), Type: typ})
+	}
+}
+
+// of returns the Go type of a symbol's value: %token_type for terminals,
+// and %type, %default_type or %token_type, in that order, for nonterminals.
+func (t *valueTypes) of(sym *grammar.Symbol) string {
+	if sym.IsTerminal() {
+		return t.token
+	} else if typ, ok := t.byName[sym.Name]; ok && typ != "" {
+		return typ
+	}
+	return t.fallback
+}
+
+// field returns the name of the union field that holds a symbol's value.
+func (t *valueTypes) field(sym *grammar.Symbol) string {
+	return t.fields[t.index[t.of(sym)]].Name
+}
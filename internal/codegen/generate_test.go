@@ -0,0 +1,1003 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+const calcGrammar = `
+%token_type {int}
+%type list {[]int}
+%include {
+import "strconv"
+
+func itoa(n int) string { return strconv.Itoa(n) }
+}
+%left PLUS MINUS.
+%left TIMES.
+
+list(L) ::= list(M) COMMA expr(E). { L = append(M, E) }
+list(L) ::= expr(E).                { L = []int{E} }
+
+expr(A) ::= expr(B) PLUS expr(C).  { A = B + C }
+expr(A) ::= expr(B) MINUS expr(C). { A = B - C }
+expr(A) ::= expr(B) TIMES expr(C). { A = B * C }
+expr(A) ::= LPAREN expr(B) RPAREN. { A = B }
+expr ::= INTEGER.
+
+%code {
+func (p *Parser) depth() int { return len(p.stack) }
+}
+`
+
+func buildAutomaton(t *testing.T, src string) *lalr.Automaton {
+	t.Helper()
+	tokens, err := lex.Tokenize("calc.y", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	a := lalr.BuildLALR(g)
+	lalr.Resort(a)
+	return a
+}
+
+func generate(t *testing.T, a *lalr.Automaton, cfg Config) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Generate(&buf, a, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// typeCheck reports whether the generated file compiles on its own.
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "parser.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, err := conf.Check("calc", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("generated parser does not compile: %v\n%s", err, src)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a := buildAutomaton(t, calcGrammar)
+	for _, cfg := range []Config{
+		{Package: "calc", GrammarFile: "calc.y"},
+		{Package: "calc", GrammarFile: "calc.y", NoCompress: true},
+		{Package: "calc", GrammarFile: "calc.y", Coverage: true},
+	} {
+		src := generate(t, a, cfg)
+		if !bytes.HasPrefix(src, []byte("// Code generated by guanabana from calc.y. DO NOT EDIT.\n\npackage calc\n")) {
+			t.Errorf("%+v: header:\n%s", cfg, src[:min(len(src), 120)])
+		}
+		if formatted, err := format.Source(src); err != nil || !bytes.Equal(formatted, src) {
+			t.Errorf("%+v: output is not gofmt-clean (%v)", cfg, err)
+		}
+		typeCheck(t, src)
+		for _, want := range []string{
+			"\t\"strconv\"\n\t\"strings\"\n",
+			"func itoa(n int) string",
+			"PLUS    = ",
+			"type yyMinor struct {\n\tyy0 int\n\tyy1 []int\n}",
+			"Value int\n",
+			"func (p *Parser) Parse(major int, minor int) error",
+			"func (p *Parser) Result() ([]int, error)",
+			"yylhs.yy1 = append(yyrhs[0].minor.yy1, yyrhs[2].minor.yy0)",
+			"yylhs.yy0 = yyrhs[0].minor.yy0 + yyrhs[2].minor.yy0",
+			"func (p *Parser) depth() int",
+		} {
+			if !bytes.Contains(src, []byte(want)) {
+				t.Errorf("%+v: output lacks %q", cfg, want)
+			}
+		}
+		if got := bytes.Contains(src, []byte("var yyDefault")); got == cfg.NoCompress {
+			t.Errorf("%+v: packed tables = %v", cfg, got)
+		}
+		if got := bytes.Contains(src, []byte("func WriteCoverProfile")); got != cfg.Coverage {
+			t.Errorf("%+v: coverage = %v", cfg, got)
+		}
+		if cfg.Coverage && !bytes.Contains(src, []byte(`"guanabana coverage v1\ngrammar %s\nautomaton %s %d\n", "calc.y", "lalr", len(yyCoverStates)`)) {
+			t.Errorf("%+v: profile header does not record the automaton", cfg)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	cfg := Config{Package: "calc", GrammarFile: "calc.y"}
+	first := generate(t, buildAutomaton(t, calcGrammar), cfg)
+	for range 3 {
+		if next := generate(t, buildAutomaton(t, calcGrammar), cfg); !bytes.Equal(first, next) {
+			t.Fatal("output differs between runs")
+		}
+	}
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	src := `
+%name Calc.
+%token_prefix TK_.
+%extra_argument {sum *int}
+%stack_size 10.
+%syntax_error { *sum = -1 }
+prog ::= prog NUM(N). { *sum += N.(int) }
+prog ::= .
+`
+	out := string(generate(t, buildAutomaton(t, src), Config{GrammarFile: "calc.y"}))
+	for _, want := range []string{
+		"package parser\n",
+		"\tTK_NUM = 1\n",
+		"type CalcParser struct",
+		"\textra  *int\n",
+		"func NewCalcParser(sum *int) *CalcParser",
+		"make([]yyStackEntry, 1, 10)",
+		"*p.extra += yyrhs[1].minor.yy0.(int)",
+		"*p.extra = -1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "error =") {
+		t.Error("the error symbol became a token constant")
+	}
+
+	out = string(generate(t, buildAutomaton(t, "%extra_argument {sum\t*int}\nprog ::= .\n"), Config{GrammarFile: "calc.y"}))
+	if !strings.Contains(out, "func NewParser(sum *int) *Parser") {
+		t.Error("an extra argument separated by a tab is not parsed")
+	}
+	var buf bytes.Buffer
+	if err := Generate(&buf, buildAutomaton(t, "%extra_argument {sum}\nprog ::= .\n"), Config{GrammarFile: "calc.y"}); err == nil || !strings.Contains(err.Error(), "want {name Type}") {
+		t.Errorf("%%extra_argument without a type: %v", err)
+	}
+}
+
+// TestGenerateTypeMistake checks that aliases have their declared types,
+// so a mistake in an action fails to compile.
+func TestGenerateTypeMistake(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%type name {string}
+name(A) ::= ID(B). { A = B }
+`)
+	src := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y"})
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "parser.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	_, err = conf.Check("calc", fset, []*ast.File{f}, nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot use yyrhs[0].minor.yy0 (variable of type int) as string value") {
+		t.Errorf("type check: %v", err)
+	}
+}
+
+// TestGenerateSyntaxError checks that a syntax error in code copied from
+// the grammar is reported at its place in the grammar file.
+func TestGenerateSyntaxError(t *testing.T) {
+	for _, tc := range []struct {
+		src, want string
+	}{
+		{"%token_type {int}\nexpr(A) ::= ID(B). { A = B + }\n", "calc.y:2:30: expected operand, found '}'"},
+		{"%token_type {int}\nexpr(A) ::= ID(B).\n  {\n    A = (B\n  }\n", "calc.y:4:11: expected ')', found newline"},
+		{"expr ::= ID.\n%destructor expr { $$ = ( }\n", "calc.y:2:27: expected operand, found '}'"},
+		{"expr ::= ID.\n%syntax_error { if { } }\n", "calc.y:2:20: missing condition in if statement"},
+		{"expr ::= ID.\n%include {\nimport \"fmt\"\nfunc f() { fmt.Println( }\n}\n", "calc.y:4:25: expected operand, found '}'"},
+		{"expr ::= ID.\n%code { var x = }\n", "calc.y:2:17: expected operand, found 'EOF'"},
+	} {
+		a := buildAutomaton(t, tc.src)
+		err := Generate(io.Discard, a, Config{Package: "calc", GrammarFile: "calc.y"})
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%q: error %v, want %s", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestLineDirectives(t *testing.T) {
+	a := buildAutomaton(t, calcGrammar)
+	src := string(generate(t, a, Config{Package: "calc", GrammarFile: "calc.y"}))
+	for _, want := range []string{
+		"//line calc.y:7:1\nfunc itoa(n int) string",
+		"/*line calc.y:15:37*/ yylhs.yy0 = yyrhs[0].minor.yy0 + yyrhs[2].minor.yy0\n",
+		"//line calc.y:22:1\nfunc (p *Parser) depth() int",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+	restores := 0
+	for i, line := range strings.Split(src, "\n") {
+		if rest, ok := strings.CutPrefix(line, "//line calc.go:"); ok {
+			restores++
+			if want := strconv.Itoa(i + 2); rest != want {
+				t.Errorf("line %d: %s, want calc.go:%s", i+1, line, want)
+			}
+		}
+	}
+	if restores != 7 {
+		t.Errorf("%d directives back to calc.go, want 7", restores)
+	}
+
+	src = string(generate(t, a, Config{Package: "calc", GrammarFile: "calc.y", NoLines: true}))
+	if strings.Contains(src, "line calc") {
+		t.Error("NoLines output has line directives")
+	}
+}
+
+// TestLineDirectivesMapErrors checks that a compile error in an action is
+// reported at its place in the grammar.
+func TestLineDirectivesMapErrors(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+expr(A) ::= expr(B) PLUS expr(C). { A = B + C }
+expr(A) ::= ID(B).
+    {
+        A = B + "x"
+    }
+`)
+	src := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y", GrammarPath: "../grammar/calc.y"})
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "parser.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	_, err = conf.Check("calc", fset, []*ast.File{f}, nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "../grammar/calc.y:6:") {
+		t.Errorf("type check: %v", err)
+	}
+}
+
+// TestLineDirectivesMultiByte checks that columns count bytes, as the
+// compiler does, after multi-byte text on the line.
+func TestLineDirectivesMultiByte(t *testing.T) {
+	const code = `%code { /* héllo, wörld */ var _ = oops }`
+	a := buildAutomaton(t, "%token_type {int}\nname ::= ID.\n"+code+"\n")
+	src := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y"})
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "parser.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	_, err = conf.Check("calc", fset, []*ast.File{f}, nil)
+	want := "calc.y:3:" + strconv.Itoa(strings.Index(code, "oops")+1) + ": undefined: oops"
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("type check: %v, want it at %s", err, want)
+	}
+}
+
+// TestLineDirectivesAfterComments checks the positions in %include and
+// %code blocks that start with comments, which gofmt keeps above the
+// //line directives.
+func TestLineDirectivesAfterComments(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%include {
+    import "strconv"
+
+    // include is a helper.
+    /* It has two comments. */
+    var include int = strconv.Itoa(1)
+}
+list ::= .
+%code {
+// code is a helper.
+// It has two comment lines.
+var code int = "oops"
+}
+`)
+	diags, err := Check(a, Config{Package: "calc", GrammarFile: "calc.y"}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Pos.String())
+	}
+	if want := []string{"calc.y:8:23", "calc.y:14:16"}; !slices.Equal(got, want) {
+		t.Errorf("errors at %v, want %v: %v", got, want, diags)
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	a := buildAutomaton(t, `x(A) ::= y(B) z(C). { A.B = B + C // B
+	s := "C"; _ = s; A = C.B }
+y ::= Y.
+z ::= Z.
+`)
+	r := a.Grammar.Rules[0]
+	got, err := substitute(r, newTypes(a.Grammar), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "yylhs.yy0.B = yyrhs[0].minor.yy0 + yyrhs[1].minor.yy0 // B\n\ts := \"C\"; _ = s; yylhs.yy0 = yyrhs[1].minor.yy0.B"
+	if got != want {
+		t.Errorf("substitute:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestSplitImports(t *testing.T) {
+	imports, rest, off, err := splitImports(`
+import "strings"
+import (
+	str "strconv"
+	"fmt"
+)
+
+var x = 1
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(imports, ","); got != `"strings",str "strconv","fmt"` {
+		t.Errorf("imports = %s", got)
+	}
+	if rest != "var x = 1" || off != 52 {
+		t.Errorf("rest = %q at %d", rest, off)
+	}
+	merged := mergeImports(imports, []string{`"errors"`, `"fmt"`})
+	if got := strings.Join(merged, ","); got != `"errors","fmt",str "strconv","strings"` {
+		t.Errorf("merged = %s", got)
+	}
+}
+
+// TestGeneratedParserRuns builds the generated parser with a small driver
+// and checks what it computes.
+func TestGeneratedParserRuns(t *testing.T) {
+	a := buildAutomaton(t, calcGrammar)
+	for _, cfg := range []Config{
+		{Package: "main", GrammarFile: "calc.y"},
+		{Package: "main", GrammarFile: "calc.y", NoCompress: true},
+	} {
+		out := runProgram(t, map[string]string{
+			"parser.go": string(generate(t, a, cfg)),
+			"main.go": `package main
+
+import "fmt"
+
+type tok = Token
+
+func run(toks ...tok) {
+	p := NewParser()
+	for _, t := range append(toks, tok{}) {
+		if err := p.Parse(t.Type, t.Value); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+	}
+	v, err := p.Result()
+	fmt.Println(v, err)
+}
+
+func main() {
+	// 1+2*3, (1+2)*3, 4-1-1
+	run(tok{INTEGER, 1}, tok{PLUS, 0}, tok{INTEGER, 2}, tok{TIMES, 0}, tok{INTEGER, 3},
+		tok{COMMA, 0}, tok{LPAREN, 0}, tok{INTEGER, 1}, tok{PLUS, 0}, tok{INTEGER, 2}, tok{RPAREN, 0}, tok{TIMES, 0}, tok{INTEGER, 3},
+		tok{COMMA, 0}, tok{INTEGER, 4}, tok{MINUS, 0}, tok{INTEGER, 1}, tok{MINUS, 0}, tok{INTEGER, 1})
+	run(tok{INTEGER, 1}, tok{PLUS, 0}, tok{PLUS, 0})
+	run(tok{INTEGER, 1}, tok{PLUS, 0})
+}
+`,
+		})
+		want := "[7 9 2] <nil>\n" +
+			"error: syntax error near PLUS, expected one of: LPAREN, INTEGER\n" +
+			"error: syntax error near $, expected one of: LPAREN, INTEGER\n"
+		if out != want {
+			t.Errorf("%+v: output:\n%s\nwant:\n%s", cfg, out, want)
+		}
+	}
+}
+
+// runProgram writes the files of a main package, with a go.mod for module
+// "prog", to a temporary directory and returns what "go run" with the
+// given flags prints. It skips the test under -short or without the go
+// command.
+func runProgram(t *testing.T, files map[string]string, flags ...string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	write := func(name, data string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module prog\n\ngo 1.23\n")
+	for name, data := range files {
+		write(name, data)
+	}
+	cmd := exec.Command(goTool, append(append([]string{"run"}, flags...), ".")...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, out)
+	}
+	return string(out)
+}
+
+// TestCoverageConcurrent checks that no count is lost when parsers run
+// in several goroutines, under the race detector if cgo is available.
+func TestCoverageConcurrent(t *testing.T) {
+	var flags []string
+	if out, err := exec.Command("go", "env", "CGO_ENABLED").Output(); err == nil && strings.TrimSpace(string(out)) == "1" {
+		flags = append(flags, "-race")
+	}
+	a := buildAutomaton(t, `
+%token_type {int}
+sum(A) ::= sum(B) PLUS NUM(C). { A = B + C }
+sum ::= NUM.
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "sum.y", Coverage: true})),
+		"main.go": `package main
+
+import (
+	"os"
+	"sync"
+)
+
+func main() {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				p := NewParser()
+				for _, tok := range []int{NUM, PLUS, NUM, 0} {
+					p.Parse(tok, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	WriteCoverProfile(os.Stdout)
+}
+`,
+	}, flags...)
+	if want := "grammar sum.y\nautomaton lalr 5\nrule 0 8000\nrule 1 8000\n"; !strings.Contains(out, want) {
+		t.Errorf("output:\n%s\nwant it to contain:\n%s", out, want)
+	}
+}
+
+// TestGenerateTokens builds a program whose token codes come from a
+// separate package, as a lexer package would use them.
+func TestGenerateTokens(t *testing.T) {
+	src := `
+%name Calc.
+%token_prefix TK_.
+%token_type {int}
+sum(A) ::= sum(B) PLUS NUM(C). { A = B + C }
+sum ::= NUM.
+`
+	a := buildAutomaton(t, src)
+	var tokens bytes.Buffer
+	if err := GenerateTokens(&tokens, a, Config{Package: "calctoken", GrammarFile: "calc.y"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package calctoken\n",
+		"type CalcCode int\n",
+		"\tTK_PLUS CalcCode = 1\n",
+		"\t\"NUM\",\n",
+	} {
+		if !strings.Contains(tokens.String(), want) {
+			t.Errorf("token file lacks %q:\n%s", want, tokens.String())
+		}
+	}
+	typeCheck(t, tokens.Bytes())
+
+	out := runProgram(t, map[string]string{
+		"calctoken/calc.go": tokens.String(),
+		"parser.go":         string(generate(t, a, Config{Package: "main", GrammarFile: "calc.y"})),
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"prog/calctoken"
+)
+
+func main() {
+	p := NewCalcParser()
+	for _, c := range []calctoken.CalcCode{calctoken.TK_NUM, calctoken.TK_PLUS, calctoken.TK_NUM, 0} {
+		fmt.Print(c, " ")
+		if err := p.Parse(int(c), 2); err != nil {
+			fmt.Println(err)
+		}
+	}
+	fmt.Println(calctoken.CalcCode(9))
+	fmt.Println(p.Result())
+}
+`,
+	})
+	if want := "NUM PLUS NUM $ CalcCode(9)\n4 <nil>\n"; out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestTrace compares the trace of a short parse with the expected log.
+func TestTrace(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%left PLUS.
+sum(A) ::= sum(B) PLUS NUM(C). { A = B + C }
+sum ::= NUM.
+`)
+	src := generate(t, a, Config{Package: "main", GrammarFile: "sum.y", Trace: true})
+	if off := generate(t, a, Config{Package: "main", GrammarFile: "sum.y"}); bytes.Contains(off, []byte("SetTrace")) {
+		t.Error("parser without Trace has SetTrace")
+	}
+	out := runProgram(t, map[string]string{
+		"parser.go": string(src),
+		"main.go": `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	p := NewParser()
+	p.SetTrace(os.Stdout, "> ")
+	for _, tok := range []Token{{NUM, 1}, {PLUS, 0}, {NUM, 2}, {}} {
+		p.Feed(tok)
+	}
+	fmt.Println(p.Result())
+	p.Reset()
+	p.Feed(Token{Type: PLUS})
+	p.SetTrace(nil, "")
+	p.Reset()
+	p.Feed(Token{Type: NUM})
+}
+`,
+	})
+	want := `> Input NUM
+> Shift NUM, go to state 3
+> Stack [0 NUM:3]
+> Input PLUS
+> Reduce 1 [sum ::= NUM.], pop 1
+> Goto sum, go to state 1
+> Shift PLUS, go to state 2
+> Stack [0 sum:1 PLUS:2]
+> Input NUM
+> Shift NUM, go to state 4
+> Stack [0 sum:1 PLUS:2 NUM:4]
+> Input $
+> Reduce 0 [sum ::= sum PLUS NUM.], pop 3
+> Goto sum, go to state 1
+> Accept
+> Stack [0]
+3 <nil>
+> Input PLUS
+> Syntax error
+> Fail
+> Stack [0]
+`
+	if out != want {
+		t.Errorf("trace:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestErrorRecovery runs a parser that recovers from syntax errors with
+// the error symbol and reports them through %syntax_error.
+func TestErrorRecovery(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%type list {int}
+%include { import "fmt" }
+%syntax_error { fmt.Printf("%d: unexpected %s, expected %v\n", pos, yySymbolName[major], expected) }
+%parse_failure { fmt.Println("giving up") }
+%parse_accept { fmt.Println("accepted") }
+list(A) ::= list(B) NUM(C) SEMI. { A = B + C }
+list(A) ::= list(B) error SEMI.   { A = B + 100 }
+list(A) ::= .                     { A = 0 }
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "list.y", Trace: true})),
+		"main.go": `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func run(trace bool, toks ...int) {
+	p := NewParser()
+	if trace {
+		p.SetTrace(os.Stdout, "> ")
+	}
+	for _, tok := range append(toks, 0) {
+		if err := p.Parse(tok, 1); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+	}
+	fmt.Println(p.Result())
+}
+
+func main() {
+	run(true, NUM, NUM, SEMI)
+	run(false, NUM, SEMI, NUM, NUM, NUM, SEMI, NUM, SEMI)
+	run(false, NUM, NUM, NUM, SEMI, NUM, NUM, SEMI)
+	run(false, NUM)
+}
+`,
+	})
+	want := `> Input NUM
+> Reduce 2 [list ::=.], pop 0
+> Goto list, go to state 1
+> Shift NUM, go to state 2
+> Stack [0 list:1 NUM:2]
+> Input NUM
+> Syntax error
+2: unexpected NUM, expected [SEMI]
+> Pop NUM
+> Shift error, go to state 3
+> Syntax error
+> Discard NUM
+> Stack [0 list:1 error:3]
+> Input SEMI
+> Shift SEMI, go to state 5
+> Stack [0 list:1 error:3 SEMI:5]
+> Input $
+> Reduce 1 [list ::= list error SEMI.], pop 3
+> Goto list, go to state 1
+> Accept
+accepted
+> Stack [0]
+100 syntax error near NUM, expected SEMI
+4: unexpected NUM, expected [SEMI]
+accepted
+102 syntax error near NUM, expected SEMI
+2: unexpected NUM, expected [SEMI]
+accepted
+200 syntax error near NUM, expected SEMI
+2: unexpected $, expected [SEMI]
+giving up
+error: syntax error near $, expected SEMI
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestRecoveryFailureDestroysToken checks that the token a parse gives up
+// on is destroyed when no state on the stack shifts the error symbol.
+func TestRecoveryFailureDestroysToken(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%include { import "fmt" }
+%token_destructor { fmt.Println("destroy", $$) }
+prog ::= BEGIN stmts END.
+stmts ::= stmts stmt.
+stmts ::= .
+stmt ::= NUM SEMI.
+stmt ::= error SEMI.
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "prog.y"})),
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	p := NewParser()
+	fmt.Println(p.Parse(NUM, 7))
+}
+`,
+	})
+	want := `destroy 7
+syntax error near NUM, expected BEGIN
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestExpectedTokens checks the expected tokens of syntax errors, with
+// %token aliases, when a default reduction is made on the bad token.
+func TestExpectedTokens(t *testing.T) {
+	a := buildAutomaton(t, `
+%token ID LPAREN "(" RPAREN ")" SEMI ";".
+stmt ::= expr SEMI.
+expr ::= ID.
+expr ::= ID LPAREN RPAREN.
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "stmt.y"})),
+		"main.go": `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func run(toks ...int) {
+	p := NewParser()
+	for _, tok := range append(toks, 0) {
+		if err := p.Parse(tok, nil); err != nil {
+			var serr *SyntaxError
+			if errors.As(err, &serr) {
+				fmt.Printf("%d: %q %q\n", serr.Pos, serr.Token, serr.Expected)
+			}
+			fmt.Println(err)
+			return
+		}
+	}
+}
+
+func main() {
+	run(ID, RPAREN)
+	run(ID, LPAREN, SEMI)
+	run(SEMI)
+}
+`,
+	})
+	want := `2: ")" ["(" ";"]
+syntax error near ), expected one of: (, ;
+3: ";" [")"]
+syntax error near ;, expected )
+1: ";" ["ID"]
+syntax error near ;, expected ID
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestFallbackAndWildcard checks that a token with no action takes the
+// action of its %fallback token and, failing that, of the %wildcard token,
+// with packed and plain tables alike.
+func TestFallbackAndWildcard(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {string}
+%include { import "fmt" }
+%token NUM.
+%fallback ID IF WHILE.
+%wildcard ANY.
+program ::= stmts.
+stmts ::= stmts stmt.
+stmts ::= .
+stmt ::= IF ID(A) SEMI. { fmt.Println("if", A) }
+stmt ::= ID(A) SEMI.    { fmt.Println("id", A) }
+stmt ::= ANY(A) SEMI.   { fmt.Println("any", A) }
+`)
+	main := `package main
+
+import "fmt"
+
+type tok struct {
+	major int
+	minor string
+}
+
+func run(toks ...tok) {
+	p := NewParser()
+	for _, tok := range append(toks, tok{}) {
+		if err := p.Parse(tok.major, tok.minor); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+}
+
+func main() {
+	run(tok{IF, "if"}, tok{WHILE, "while"}, tok{SEMI, ";"}, tok{WHILE, "while"}, tok{SEMI, ";"})
+	run(tok{NUM, "1"}, tok{SEMI, ";"}, tok{IF, "if"}, tok{IF, "if"}, tok{SEMI, ";"})
+	run(tok{IF, "if"}, tok{NUM, "1"}, tok{SEMI, ";"})
+}
+`
+	want := `if while
+id while
+any 1
+if if
+syntax error near NUM, expected one of: ID, IF, WHILE
+`
+	for _, noCompress := range []bool{false, true} {
+		cfg := Config{Package: "main", GrammarFile: "stmt.y", NoCompress: noCompress}
+		out := runProgram(t, map[string]string{
+			"parser.go": string(generate(t, a, cfg)),
+			"main.go":   main,
+		})
+		if out != want {
+			t.Errorf("NoCompress %v: output:\n%s\nwant:\n%s", noCompress, out, want)
+		}
+	}
+}
+
+// TestStackLimit checks that the stack grows past %stack_size, that
+// %stack_overflow runs when it would pass %stack_size_limit, and that the
+// values left on it are destroyed on overflow, on Reset and, with the bad
+// token, on a syntax error.
+func TestStackLimit(t *testing.T) {
+	a := buildAutomaton(t, `
+%token_type {int}
+%type expr {int}
+%include { import "fmt" }
+%stack_size 4
+%stack_size_limit 10
+%stack_overflow { fmt.Println("overflow") }
+%token_destructor { freed += $$ }
+%destructor expr { freed += 100 * $$ }
+expr(A) ::= LPAREN expr(B) RPAREN. { A = B + 1 }
+expr(A) ::= NUM(B).                { A = B }
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "expr.y"})),
+		"main.go": `package main
+
+import "fmt"
+
+var freed int
+
+func nest(depth int) []int {
+	var toks []int
+	for range depth {
+		toks = append(toks, LPAREN)
+	}
+	toks = append(toks, NUM)
+	for range depth {
+		toks = append(toks, RPAREN)
+	}
+	return append(toks, 0)
+}
+
+func run(p *Parser, toks []int) {
+	freed = 0
+	for _, tok := range toks {
+		if err := p.Parse(tok, 1); err != nil {
+			fmt.Println("error:", err, "freed:", freed)
+			return
+		}
+	}
+	r, _ := p.Result()
+	fmt.Println(r, "freed:", freed)
+}
+
+func main() {
+	p := NewParser()
+	run(p, nest(7))
+	p.Reset()
+	run(p, nest(8))
+	p.Reset()
+	run(p, nest(20))
+	p.Reset()
+	freed = 0
+	for _, tok := range nest(3)[:4] {
+		p.Parse(tok, 1)
+	}
+	p.Reset()
+	fmt.Println("reset freed:", freed)
+	run(p, nest(2))
+	p.Reset()
+	run(p, []int{NUM, NUM, 0})
+}
+`,
+	})
+	want := `8 freed: 0
+overflow
+error: stack overflow: more than 10 entries freed: 109
+overflow
+error: stack overflow: more than 10 entries freed: 10
+reset freed: 4
+3 freed: 0
+error: syntax error near NUM, expected $ freed: 101
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+// TestCST checks the syntax tree of a parser generated with CST, which
+// leaves out the grammar's types and code.
+func TestCST(t *testing.T) {
+	a := buildAutomaton(t, `
+%name Calc
+%token_type {float64}
+%type list {[]float64}
+%include { import "math" }
+%token_destructor { _ = $$ }
+%syntax_error { panic("not reached") }
+%left PLUS.
+list ::= .
+list(A) ::= list(B) expr(C) SEMI. { A = append(B, C) }
+expr(A) ::= expr(B) PLUS expr(C). { A = math.Max(B, C) }
+expr ::= NUM.
+`)
+	out := runProgram(t, map[string]string{
+		"parser.go": string(generate(t, a, Config{Package: "main", GrammarFile: "list.y", CST: true})),
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	p := NewCalcParser()
+	for col, c := range "1 + 2;" {
+		if c == ' ' {
+			continue
+		}
+		leaf := CalcLeaf{Text: string(c), Pos: CalcPosition{File: "in", Line: 1, Column: col + 1}}
+		code := map[rune]int{'+': PLUS, ';': SEMI}[c]
+		if code == 0 {
+			code = NUM
+			leaf.Trailing = []CalcTrivia{{Text: " ", Pos: CalcPosition{Line: 1, Column: col + 2}}}
+		}
+		if err := p.Parse(code, leaf); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if err := p.End(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	tree, err := p.Result()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(tree.Tree())
+	fmt.Println(tree)
+	num := tree.Children[1].Children[0].Children[0]
+	fmt.Println(num.Name(), num.Rule, num.Token.Trailing[0].Pos)
+}
+`,
+	})
+	want := `list ::= list expr SEMI.
+  list ::=.
+  expr ::= expr PLUS expr.
+    expr ::= NUM.
+      NUM "1" in:1:1
+    PLUS "+" in:1:3
+    expr ::= NUM.
+      NUM "2" in:1:5
+  SEMI ";" in:1:6
+(list (list) (expr (expr (NUM "1")) (PLUS "+") (expr (NUM "2"))) (SEMI ";"))
+NUM -1 1:2
+`
+	if out != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// restoreMarker stands for a //line directive back to the generated file
+// until the file is formatted and its line numbers are known.
+const restoreMarker = "//line guanabana-restore:1"
+
+// lineDirectives writes the //line directives that map code copied from
+// the grammar back to it, so compiler errors, panics and coverage in that
+// code point at the grammar file. A nil *lineDirectives writes none.
+type lineDirectives struct {
+	file string // grammar file name
+}
+
+func newLineDirectives(cfg Config) *lineDirectives {
+	if cfg.NoLines {
+		return nil
+	}
+	file := cfg.GrammarPath
+	if file == "" {
+		file = cfg.GrammarFile
+	}
+	return &lineDirectives{file: filepath.ToSlash(file)}
+}
+
+// directive returns a //line directive, for a line of its own, giving the
+// position of the code at offset off in a block that opens at pos.
+func (l *lineDirectives) directive(pos lex.Position, code string, off int) string {
+	if l == nil || pos.Line == 0 {
+		return ""
+	}
+	line, col := positionIn(pos, code, off)
+	return fmt.Sprintf("//line %s:%d:%d", l.file, line, col)
+}
+
+// comment returns a /*line*/ comment to put before the code at offset off
+// in a block that opens at pos. gofmt puts a space between the comment
+// and the code, and the comment gives the position of that space.
+func (l *lineDirectives) comment(pos lex.Position, code string, off int) string {
+	if l == nil || pos.Line == 0 {
+		return ""
+	}
+	line, col := positionIn(pos, code, off)
+	return fmt.Sprintf("/*line %s:%d:%d*/", l.file, line, max(col-1, 1))
+}
+
+// leadingComment returns the offset of the first code in s that follows
+// the comments and white space starting it.
+func leadingComment(s string) int {
+	n := 0
+	for {
+		rest := strings.TrimLeftFunc(s[n:], unicode.IsSpace)
+		i := len(s) - len(rest)
+		var end int
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			end = strings.IndexByte(rest, '\n')
+		case strings.HasPrefix(rest, "/*"):
+			if end = strings.Index(rest, "*/"); end >= 0 {
+				end += len("*/")
+			}
+		default:
+			return i
+		}
+		if end < 0 {
+			return len(s)
+		}
+		n = i + end
+	}
+}
+
+// positionIn returns the line and column of offset off in the code of a
+// block whose opening brace is at pos. Columns count bytes, as //line
+// directives and go/scanner do.
+func positionIn(pos lex.Position, code string, off int) (line, col int) {
+	line, col = pos.Line, pos.Column+1
+	for i := 0; i < off; i++ {
+		if code[i] == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// restoreLines replaces each restore marker with a //line directive that
+// gives the next line its own position in the generated file.
+func restoreLines(src []byte, marker, file string) []byte {
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		if string(line) == marker {
+			lines[i] = []byte("//line " + file + ":" + strconv.Itoa(i+2))
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
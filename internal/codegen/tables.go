@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// encode packs an action into one integer: the kind in the low two bits
+// and the target state or rule index above them. The kinds use the values
+// of lalr.ActionKind, so zero is an error.
+func encode(act lalr.Action) int {
+	switch act.Kind {
+	case lalr.ActionShift:
+		return int(act.Kind) | act.State<<2
+	case lalr.ActionReduce:
+		return int(act.Kind) | act.RuleIndex<<2
+	}
+	return int(act.Kind)
+}
+
+// newTables renders the parse tables as Go composite literals.
+//
+// In the packed tables a missing row has the offset -NumSymbols, which
+// puts every lookup before the start of the entries, so the generated
+// lookup needs no special case for it.
+func newTables(a *lalr.Automaton, g *grammar.Grammar, noCompress bool) Tables {
+	table := lalr.BuildTables(a, g)
+	applyFallbacks(table, g)
+	var all []int
+	list := func(vals []int) string {
+		all = append(all, vals...)
+		return literal(vals)
+	}
+
+	var t Tables
+	if noCompress {
+		var action, gotos [][]int
+		for s := range table.NumStates {
+			row := make([]int, table.NumTerminals)
+			for term, act := range table.Action[s] {
+				row[term] = encode(act)
+			}
+			action = append(action, row)
+			gotos = append(gotos, table.Goto[s])
+		}
+		for _, row := range action {
+			t.Action = append(t.Action, list(row))
+		}
+		for _, row := range gotos {
+			t.Goto = append(t.Goto, list(row))
+		}
+	} else {
+		c := lalr.Compress(table)
+		noBase := -g.Symbols.NumSymbols()
+		bases := func(in []int) []int {
+			out := make([]int, len(in))
+			for i, b := range in {
+				if b == lalr.NoBase {
+					b = noBase
+				}
+				out[i] = b
+			}
+			return out
+		}
+		t.Default = list(encodeAll(c.Default))
+		t.ActionBase = list(bases(c.ActionBase))
+		t.GotoBase = list(bases(c.GotoBase))
+		t.Entry = list(encodeAll(c.Entries))
+		t.Check = list(c.Check)
+	}
+
+	var lhs, size []int
+	for _, r := range g.Rules {
+		lhs = append(lhs, r.LHS.ID)
+		size = append(size, len(r.RHS))
+	}
+	t.RuleLHS = list(lhs)
+	t.RuleSize = list(size)
+	t.Type = intType(all)
+	return t
+}
+
+// applyFallbacks fills the error cells of the ACTION table the way Lemon's
+// parser looks up actions: a terminal with no action takes the action of
+// its %fallback terminal, and of that one's, and failing that the action
+// of the %wildcard terminal. The end of input and the error symbol never
+// fall back, and neither do cells that %nonassoc made errors.
+func applyFallbacks(table *lalr.ParseTable, g *grammar.Grammar) {
+	fallbacks, wildcard := g.Fallbacks(), g.Wildcard()
+	if len(fallbacks) == 0 && wildcard == nil {
+		return
+	}
+	for state, row := range table.Action {
+		orig := slices.Clone(row)
+		for term := range row {
+			sym := g.Symbols.Symbol(term)
+			if orig[term].Kind != lalr.ActionError || sym == g.EOF || sym.Name == grammar.ErrorName ||
+				slices.Contains(table.Nonassoc[state], term) {
+				continue
+			}
+			// The chain is bounded so that a cycle of fallbacks ends.
+			to := fallbacks[sym]
+			for range len(fallbacks) {
+				if to == nil || orig[to.ID].Kind != lalr.ActionError {
+					break
+				}
+				to = fallbacks[to]
+			}
+			if to != nil && orig[to.ID].Kind != lalr.ActionError {
+				row[term] = orig[to.ID]
+			} else if wildcard != nil && sym != wildcard {
+				row[term] = orig[wildcard.ID]
+			}
+		}
+	}
+}
+
+func encodeAll(acts []lalr.Action) []int {
+	out := make([]int, len(acts))
+	for i, act := range acts {
+		out[i] = encode(act)
+	}
+	return out
+}
+
+// intType returns the smallest signed integer type that holds every value.
+func intType(vals []int) string {
+	lo, hi := 0, 0
+	for _, v := range vals {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	switch {
+	case lo >= math.MinInt8 && hi <= math.MaxInt8:
+		return "int8"
+	case lo >= math.MinInt16 && hi <= math.MaxInt16:
+		return "int16"
+	}
+	return "int32"
+}
+
+// literal returns the elements of a composite literal, in braces, sixteen
+// to a line.
+func literal(vals []int) string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, v := range vals {
+		if i%16 == 0 {
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(strconv.Itoa(v))
+		sb.WriteString(",")
+	}
+	sb.WriteString("\n}")
+	return sb.String()
+}
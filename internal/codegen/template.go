@@ -0,0 +1,850 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import "text/template"
+
+// parserTemplate writes the parser unless Config.Template replaces it.
+var parserTemplate = template.Must(ParseTemplate("parser", DefaultTemplate))
+
+// ParseTemplate parses the text of a parser template; name appears in
+// its error messages. The template is executed with a *Data and must
+// write a Go source file, which Generate runs through go/format, so blank
+// lines and indentation need not be exact. To keep //line directives
+// balanced, a template that writes an action should follow it with
+// {{$.Restore}} on a line of its own, as DefaultTemplate does, and the
+// same for Include and Code.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Parse(text)
+}
+
+// DefaultTemplate is the text of the template that writes the parser;
+// "guanabana template dump" prints it as a starting point for -T.
+const DefaultTemplate = `// Code generated by guanabana from {{.Grammar}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+{{if or .IncludeComment .Include}}
+{{- if .IncludeComment}}
+{{.IncludeComment}}
+{{- end}}
+{{- if .IncludeLine}}
+{{.IncludeLine}}
+{{- end}}
+{{.Include}}
+{{- if .Restore}}
+{{.Restore}}
+{{- end}}
+{{end}}
+{{- if .Tokens}}
+// Token codes for Feed and Parse. The end of the input is token 0.
+const (
+{{- range .Tokens}}
+	{{.Name}} = {{.ID}}
+{{- end}}
+)
+{{end}}
+// {{.Token}} is a token from the lexer: its code and its value.
+type {{.Token}} struct {
+	Type  int
+	Value {{.TokenType}}
+}
+
+// {{.ErrorType}} is the error for a token that the parser cannot accept.
+type {{.ErrorType}} struct {
+	Token    string   // name of the token
+	Pos      int      // number of the token in the input, from 1
+	Expected []string // names of the tokens that the parser could accept instead
+}
+
+// Error returns e.g. "syntax error near RPAREN, expected one of: NUMBER, LPAREN".
+func (e *{{.ErrorType}}) Error() string {
+	msg := "syntax error near " + e.Token
+	switch len(e.Expected) {
+	case 0:
+	case 1:
+		msg += ", expected " + e.Expected[0]
+	default:
+		msg += ", expected one of: " + strings.Join(e.Expected, ", ")
+	}
+	return msg
+}
+{{- if .CST}}
+
+// {{.Node}} is a node of the concrete syntax tree that the parser builds:
+// the reduction of a rule, with a child for each symbol of its right-hand
+// side, or a token.
+type {{.Node}} struct {
+	Symbol   int      // symbol ID
+	Rule     int      // index of the rule reduced, or -1 for a token
+	Children []*{{.Node}}
+	Token    *{{.Leaf}} // the token, for a token node
+}
+
+// {{.Leaf}} is a token as the lexer found it, the value passed to Feed
+// and Parse with its code.
+type {{.Leaf}} struct {
+	Text     string
+	Pos      {{.Position}}
+	Leading  []{{.Trivia}} // white space and comments before the token
+	Trailing []{{.Trivia}} // white space and comments after it
+}
+
+// {{.Position}} records where a token or trivia was found in the source.
+type {{.Position}} struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String returns "file:line:column", or "line:column" without a file.
+func (p {{.Position}}) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// {{.Trivia}} is source text that the grammar does not see, such as white
+// space and comments.
+type {{.Trivia}} struct {
+	Text string
+	Pos  {{.Position}}
+}
+
+// Name returns the name of the node's symbol.
+func (n *{{.Node}}) Name() string {
+	return yySymbolName[n.Symbol]
+}
+
+// String returns the tree as an S-expression: a list of the symbol and the
+// children for a rule, and of the symbol and the quoted text for a token,
+// e.g. (expr (expr (NUM "1")) (PLUS "+") (expr (NUM "2"))).
+func (n *{{.Node}}) String() string {
+	var sb strings.Builder
+	n.writeSExpr(&sb)
+	return sb.String()
+}
+
+func (n *{{.Node}}) writeSExpr(sb *strings.Builder) {
+	sb.WriteString("(" + n.Name())
+	if n.Token != nil {
+		fmt.Fprintf(sb, " %q", n.Token.Text)
+	}
+	for _, c := range n.Children {
+		sb.WriteByte(' ')
+		c.writeSExpr(sb)
+	}
+	sb.WriteByte(')')
+}
+
+// Tree returns the tree indented by two spaces a level, a line per node:
+// the rule for a rule, and the symbol, the quoted text and the position
+// for a token.
+func (n *{{.Node}}) Tree() string {
+	var sb strings.Builder
+	n.writeTree(&sb, "")
+	return sb.String()
+}
+
+func (n *{{.Node}}) writeTree(sb *strings.Builder, indent string) {
+	if n.Token != nil {
+		fmt.Fprintf(sb, "%s%s %q %s\n", indent, n.Name(), n.Token.Text, n.Token.Pos)
+		return
+	}
+	fmt.Fprintf(sb, "%s%s\n", indent, yyRuleText[n.Rule])
+	for _, c := range n.Children {
+		c.writeTree(sb, indent+"  ")
+	}
+}
+
+// yyNode returns the node for the reduction of a rule, given the stack
+// entries of its right-hand side.
+func yyNode(rule int, rhs []yyStackEntry) *{{.Node}} {
+	n := &{{.Node}}{Symbol: int(yyRuleLHS[rule]), Rule: rule, Children: make([]*{{.Node}}, len(rhs))}
+	for i, e := range rhs {
+		if e.major < yyNumTerminals {
+			n.Children[i] = &{{.Node}}{Symbol: e.major, Rule: -1, Token: &e.minor.{{(index .Fields 0).Name}}}
+		} else {
+			n.Children[i] = e.minor.{{(index .Fields 1).Name}}
+		}
+	}
+	return n
+}
+{{- end}}
+
+// {{.Parser}} is a push parser: the lexer drives it by passing each token to
+// Feed (or Parse), then calls End at the end of the input and Result for
+// the value of the start symbol.
+type {{.Parser}} struct {
+	stack  []yyStackEntry
+	result {{.Result.Type}}
+	done   bool
+	err    error
+	pos    int // number of the current token in the input, from 1
+
+	// The stack as it was when the current token arrived, before the
+	// reductions on it, for the expected tokens of a syntax error: the
+	// entries below base are unchanged, and popped holds the states of
+	// those above it, top first.
+	base   int
+	popped []int
+{{- if .ErrorSymbol}}
+	errCount  int   // tokens to shift before another syntax error is reported
+	recovered error // first syntax error reported and recovered from
+{{- end}}
+{{- if .Extra}}
+	extra  {{.Extra.Type}}
+{{- end}}
+{{- if .Trace}}
+	trace       io.Writer
+	tracePrefix string
+{{- end}}
+}
+
+// yyStackEntry is one entry of the parser stack.
+type yyStackEntry struct {
+	state int     // state entered
+	major int     // symbol ID
+	minor yyMinor // semantic value
+}
+
+// yyMinor holds the value of a symbol in the field for its type.
+type yyMinor struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+
+// New{{.Parser}} returns a parser ready for the first token.
+func New{{.Parser}}({{if .Extra}}{{.Extra.Name}} {{.Extra.Type}}{{end}}) *{{.Parser}} {
+	p := &{{.Parser}}{ {{- if .Extra}}extra: {{.Extra.Name}}{{end -}} }
+	p.stack = make([]yyStackEntry, 1, {{.StackSize}})
+	return p
+}
+
+// Reset makes the parser ready for a new input, keeping its stack's
+// memory{{if .Extra}}, its {{.Extra.Name}}{{end}}{{if .Trace}} and its trace settings{{end}}.{{if .Destructors}} The values of
+// a parse in progress are destroyed.{{end}}
+func (p *{{.Parser}}) Reset() {
+	p.popAll()
+	*p = {{.Parser}}{stack: p.stack{{if .Extra}}, extra: p.extra{{end}}{{if .Trace}}, trace: p.trace, tracePrefix: p.tracePrefix{{end}}}
+}
+{{- if .Trace}}
+
+// SetTrace makes the parser log each token, shift, reduce and goto, and
+// the stack after each token, to w with every line starting with prefix.
+// A nil w turns tracing off.
+func (p *{{.Parser}}) SetTrace(w io.Writer, prefix string) {
+	p.trace, p.tracePrefix = w, prefix
+}
+
+// tracef writes a line of the trace.
+func (p *{{.Parser}}) tracef(format string, args ...any) {
+	if p.trace != nil {
+		fmt.Fprintf(p.trace, "%s"+format+"\n", append([]any{p.tracePrefix}, args...)...)
+	}
+}
+
+// traceStack writes the symbols on the stack, each with its state.
+func (p *{{.Parser}}) traceStack() {
+	if p.trace == nil {
+		return
+	}
+	var b strings.Builder
+	for i, e := range p.stack {
+		if i > 0 {
+			fmt.Fprintf(&b, " %s:%d", yySymbolName[e.major], e.state)
+		} else {
+			fmt.Fprintf(&b, "%d", e.state)
+		}
+	}
+	p.tracef("Stack [%s]", b.String())
+}
+{{- end}}
+
+// Feed passes the next token to the parser.
+func (p *{{.Parser}}) Feed(tok {{.Token}}) error {
+	return p.Parse(tok.Type, tok.Value)
+}
+
+// End tells the parser that the input is complete. It is the same as
+// passing token 0, and does nothing if the input was already accepted.
+func (p *{{.Parser}}) End() error {
+	if p.done && p.err == nil {
+		return nil
+	}
+	var minor {{.TokenType}}
+	return p.Parse(0, minor)
+}
+
+// FeedAll passes every token of a sequence to the parser, ends the input
+// and returns the result. The sequence may include the end-of-input token.
+// It stops at the first error.
+func (p *{{.Parser}}) FeedAll(tokens iter.Seq[{{.Token}}]) ({{.Result.Type}}, error) {
+	for tok := range tokens {
+		if err := p.Feed(tok); err != nil {
+			return p.result, err
+		} else if p.done {
+			break
+		}
+	}
+	if err := p.End(); err != nil {
+		return p.result, err
+	}
+	return p.Result()
+}
+
+// Parse passes the next token's code and value to the parser; token 0
+// ends the input. After a syntax error, Parse returns the same error for
+// every further token until Reset.
+func (p *{{.Parser}}) Parse(major int, minor {{.TokenType}}) error {
+	if p.err != nil {
+		return p.err
+	} else if p.done {
+		return errors.New("token after end of input")
+	} else if major < 0 || major >= yyNumTerminals {
+		p.err = fmt.Errorf("invalid token %d", major)
+		return p.err
+	}
+	p.pos++
+	p.base, p.popped = len(p.stack), p.popped[:0]
+{{- if .Trace}}
+	p.tracef("Input %s", yySymbolName[major])
+	defer p.traceStack()
+{{- end}}
+{{- if .ErrorSymbol}}
+	retried := false
+{{- end}}
+	for {
+		act := yyFindAction(p.stack[len(p.stack)-1].state, major)
+		switch act & 3 {
+		case yyShift:
+{{- if .Trace}}
+			p.tracef("Shift %s, go to state %d", yySymbolName[major], act>>2)
+{{- end}}
+			p.push(act>>2, major, yyMinor{ {{- (index .Fields 0).Name}}: minor})
+{{- if .ErrorSymbol}}
+			p.errCount = max(p.errCount-1, 0)
+{{- end}}
+{{- if .StackLimit}}
+			return p.err
+{{- else}}
+			return nil
+{{- end}}
+		case yyReduce:
+			p.reduce(act >> 2)
+{{- if .StackLimit}}
+			if p.err != nil {
+				return p.err
+			}
+{{- end}}
+		case yyAccept:
+{{- if .Trace}}
+			p.tracef("Accept")
+{{- end}}
+			p.result = p.stack[len(p.stack)-1].minor.{{.Result.Name}}
+			p.done = true
+			// The result is the caller's now, not a value to destroy.
+			clear(p.stack[1:])
+			p.stack = p.stack[:1]
+{{- if .ParseAccept.Code}}
+			p.parseAccept()
+{{- end}}
+			return nil
+		default:
+{{- if .Trace}}
+			p.tracef("Syntax error")
+{{- end}}
+{{- if .ErrorSymbol}}
+			if !p.recover(major, minor, retried) {
+				return p.err
+			}
+			retried = true
+{{- else}}
+			err := p.errorAt(major)
+{{- if .SyntaxError.Code}}
+			p.syntaxError(major, minor, err.Pos, err.Expected)
+{{- end}}
+{{- if .Destructors}}
+			p.destroy(major, yyMinor{ {{- (index .Fields 0).Name}}: minor})
+{{- end}}
+			return p.fail(err)
+{{- end}}
+		}
+	}
+}
+{{- if .ErrorSymbol}}
+
+// recover recovers from a syntax error the way yacc does. Unless another
+// error was reported within the last three tokens shifted, it reports the
+// error{{if .SyntaxError.Code}} and runs the %syntax_error code{{end}}. Then, if the token was already
+// retried or the error symbol is on top of the stack, it discards the
+// token; otherwise it pops the stack to a state that shifts the error
+// symbol and shifts it, and the token is retried. It returns whether to
+// retry the token. The parse fails when no state shifts the error symbol
+// or the token to discard is the end of the input.
+func (p *{{.Parser}}) recover(major int, minor {{.TokenType}}, retried bool) bool {
+	err := p.errorAt(major)
+	if p.errCount == 0 {
+{{- if .SyntaxError.Code}}
+		p.syntaxError(major, minor, err.Pos, err.Expected)
+{{- end}}
+		if p.recovered == nil {
+			p.recovered = err
+		}
+	}
+	p.errCount = 3
+	if retried || p.stack[len(p.stack)-1].major == yyErrorSymbol {
+		if major == 0 {
+			p.fail(err)
+			return false
+		}
+{{- if .Trace}}
+		p.tracef("Discard %s", yySymbolName[major])
+{{- end}}
+{{- if .Destructors}}
+		p.destroy(major, yyMinor{ {{- (index .Fields 0).Name}}: minor})
+{{- end}}
+		return false
+	}
+	for len(p.stack) > 1 && yyFindAction(p.stack[len(p.stack)-1].state, yyErrorSymbol)&3 != yyShift {
+		top := p.stack[len(p.stack)-1]
+{{- if .Trace}}
+		p.tracef("Pop %s", yySymbolName[top.major])
+{{- end}}
+{{- if .Destructors}}
+		p.destroy(top.major, top.minor)
+{{- end}}
+		p.stack[len(p.stack)-1] = yyStackEntry{}
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	act := yyFindAction(p.stack[len(p.stack)-1].state, yyErrorSymbol)
+	if act&3 != yyShift {
+{{- if .Destructors}}
+		p.destroy(major, yyMinor{ {{- (index .Fields 0).Name}}: minor})
+{{- end}}
+		p.fail(err)
+		return false
+	}
+{{- if .Trace}}
+	p.tracef("Shift %s, go to state %d", yySymbolName[yyErrorSymbol], act>>2)
+{{- end}}
+	p.push(act>>2, yyErrorSymbol, yyMinor{})
+	p.base, p.popped = len(p.stack), p.popped[:0]
+{{- if .StackLimit}}
+	return p.err == nil
+{{- else}}
+	return true
+{{- end}}
+}
+{{- end}}
+
+// errorAt returns the syntax error for a token.
+func (p *{{.Parser}}) errorAt(major int) *{{.ErrorType}} {
+	return &{{.ErrorType}}{Token: yySymbolName[major], Pos: p.pos, Expected: p.expected()}
+}
+
+// expected returns the names of the terminals that the parser could have
+// accepted instead of the current token. Each is tried on the stack as it
+// was when the token arrived, through any reductions, so a default
+// reduction made on the token hides nothing.
+func (p *{{.Parser}}) expected() []string {
+	stack := make([]int, 0, p.base+len(p.popped))
+	for _, e := range p.stack[:p.base] {
+		stack = append(stack, e.state)
+	}
+	for i := len(p.popped) - 1; i >= 0; i-- {
+		stack = append(stack, p.popped[i])
+	}
+	var names []string
+	for major := range yyNumTerminals {
+{{- if .ErrorSymbol}}
+		if major == yyErrorSymbol {
+			continue
+		}
+{{- end}}
+		if yyAccepts(stack, major) {
+			names = append(names, yySymbolName[major])
+		}
+	}
+	return names
+}
+
+// yyAccepts reports whether a stack of states shifts or accepts a terminal
+// after the reductions it makes on it. The stack is left unchanged: the
+// states that reductions push are kept apart.
+func yyAccepts(stack []int, major int) bool {
+	n := len(stack)
+	var pushed []int
+	top := func() int {
+		if len(pushed) > 0 {
+			return pushed[len(pushed)-1]
+		}
+		return stack[n-1]
+	}
+	for {
+		act := yyFindAction(top(), major)
+		switch act & 3 {
+		case yyShift, yyAccept:
+			return true
+		case yyReduce:
+			rule := act >> 2
+			size := int(yyRuleSize[rule])
+			k := min(size, len(pushed))
+			pushed, n = pushed[:len(pushed)-k], n-(size-k)
+			pushed = append(pushed, yyFindGoto(top(), int(yyRuleLHS[rule])))
+		default:
+			return false
+		}
+	}
+}
+
+// fail ends the parse with an error. It empties the stack{{if .ParseFailure.Code}} and runs the
+// %parse_failure code{{end}}.
+func (p *{{.Parser}}) fail(err error) error {
+{{- if .Trace}}
+	p.tracef("Fail")
+{{- end}}
+	p.err = err
+	p.popAll()
+{{- if .ParseFailure.Code}}
+	p.parseFailure()
+{{- end}}
+	return err
+}
+{{- if .StackLimit}}
+
+// overflow ends the parse when the stack is full{{if .StackOverflow.Code}}, after running the
+// %stack_overflow code{{end}}. It empties the stack{{if .Destructors}} and destroys the value that
+// did not fit{{end}}.
+func (p *{{.Parser}}) overflow(major int, minor yyMinor) {
+{{- if .Trace}}
+	p.tracef("Stack overflow")
+{{- end}}
+{{- if .StackOverflow.Code}}
+	p.stackOverflow()
+{{- end}}
+{{- if .Destructors}}
+	p.destroy(major, minor)
+{{- end}}
+	p.popAll()
+	p.err = fmt.Errorf("stack overflow: more than %d entries", yyStackLimit)
+}
+{{- end}}
+
+// popAll empties the stack down to its bottom entry{{if .Destructors}}, destroying the values
+// on it{{end}}.
+func (p *{{.Parser}}) popAll() {
+{{- if .Destructors}}
+	for i := len(p.stack) - 1; i > 0; i-- {
+		p.destroy(p.stack[i].major, p.stack[i].minor)
+	}
+{{- end}}
+	clear(p.stack[1:])
+	p.stack = p.stack[:1]
+}
+{{- if .Destructors}}
+
+// destroy runs the destructor of a value that the parser discards.
+func (p *{{.Parser}}) destroy(major int, minor yyMinor) {
+	switch major {
+{{- range .Destructors}}
+	case {{range $i, $id := .Symbols}}{{if $i}}, {{end}}{{$id}}{{end}}: // {{.Names}}
+		{{.Line}}{{.Code}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+{{- end}}
+	}
+}
+{{- end}}
+
+// Result returns the value of the start symbol once the end of the input
+// has been accepted.{{if .ErrorSymbol}} If the parser recovered from syntax errors, it returns
+// the value with the first error reported.{{end}}
+func (p *{{.Parser}}) Result() ({{.Result.Type}}, error) {
+	if p.err != nil {
+		return p.result, p.err
+	} else if !p.done {
+		return p.result, errors.New("parse incomplete: end of input not seen")
+{{- if .ErrorSymbol}}
+	} else if p.recovered != nil {
+		return p.result, p.recovered
+{{- end}}
+	}
+	return p.result, nil
+}
+{{- with .SyntaxError}}{{if .Code}}
+
+// syntaxError runs the %syntax_error code for a token the parser cannot
+// accept: major and minor are its code and value, pos its number in the
+// input, from 1, and expected the names of the tokens it could accept.
+func (p *{{$.Parser}}) syntaxError(major int, minor {{$.TokenType}}, pos int, expected []string) {
+	{{.Line}}{{.Code}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+}
+{{- end}}{{end}}
+{{- with .ParseFailure}}{{if .Code}}
+
+// parseFailure runs the %parse_failure code when the parse fails.
+func (p *{{$.Parser}}) parseFailure() {
+	{{.Line}}{{.Code}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+}
+{{- end}}{{end}}
+{{- with .StackOverflow}}{{if .Code}}
+
+// stackOverflow runs the %stack_overflow code when the stack is full.
+func (p *{{$.Parser}}) stackOverflow() {
+	{{.Line}}{{.Code}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+}
+{{- end}}{{end}}
+{{- with .ParseAccept}}{{if .Code}}
+
+// parseAccept runs the %parse_accept code when the parser accepts the
+// input.
+func (p *{{$.Parser}}) parseAccept() {
+	{{.Line}}{{.Code}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+}
+{{- end}}{{end}}
+
+// push enters a state{{if .StackLimit}}, unless the stack is full{{end}}.
+func (p *{{.Parser}}) push(state, major int, minor yyMinor) {
+{{- if .StackLimit}}
+	if len(p.stack) >= yyStackLimit {
+		p.overflow(major, minor)
+		return
+	}
+{{- end}}
+{{- if .Coverage}}
+	yyCoverStates[state].Add(1)
+{{- end}}
+	p.stack = append(p.stack, yyStackEntry{state: state, major: major, minor: minor})
+}
+
+// reduce pops the right-hand side of a rule, runs its action and pushes
+// the left-hand side. A rule whose LHS has no alias passes on the value of
+// its first RHS symbol, if that has the same type.
+func (p *{{.Parser}}) reduce(rule int) {
+{{- if .Coverage}}
+	yyCoverRules[rule].Add(1)
+{{- end}}
+	n := len(p.stack) - int(yyRuleSize[rule])
+	for ; p.base > n; p.base-- {
+		p.popped = append(p.popped, p.stack[p.base-1].state)
+	}
+{{- if .Trace}}
+	p.tracef("Reduce %d [%s], pop %d", rule, yyRuleText[rule], len(p.stack)-n)
+{{- end}}
+{{- if .RHSValues}}
+	yyrhs := p.stack[n:]
+{{- end}}
+	var yylhs yyMinor
+{{- if .CST}}
+	yylhs.{{(index .Fields 1).Name}} = yyNode(rule, yyrhs)
+{{- else}}
+	switch rule {
+{{- range .Rules}}{{if or .Action .Copy}}
+	case {{.Index}}: // {{.Text}}
+{{- if .Copy}}
+		yylhs.{{.Field}} = yyrhs[0].minor.{{.Field}}
+{{- end}}
+{{- if .Action}}
+		{{.Line}}{{.Action}}
+{{- if $.Restore}}
+{{$.Restore}}
+{{- end}}
+{{- end}}
+{{- end}}{{end}}
+	}
+{{- end}}
+	clear(p.stack[n:])
+	p.stack = p.stack[:n]
+	lhs := int(yyRuleLHS[rule])
+	state := yyFindGoto(p.stack[n-1].state, lhs)
+{{- if .Trace}}
+	p.tracef("Goto %s, go to state %d", yySymbolName[lhs], state)
+{{- end}}
+	p.push(state, lhs, yylhs)
+}
+
+// Actions are encoded with the kind in the low two bits and the target
+// state or rule index above them.
+const (
+	yyError  = 0
+	yyShift  = 1
+	yyReduce = 2
+	yyAccept = 3
+
+	yyNumTerminals = {{.NumTerminals}}
+{{- if .ErrorSymbol}}
+	yyErrorSymbol  = {{.ErrorSymbol}}
+{{- end}}
+{{- if .StackLimit}}
+	yyStackLimit   = {{.StackLimit}}
+{{- end}}
+)
+{{if .Compressed}}
+// yyFindAction returns the action for a lookahead terminal. The ACTION and
+// GOTO rows share yyEntry; yyCheck tells which row an entry belongs to,
+// and a lookahead with no entry takes the state's default action.
+func yyFindAction(state, major int) int {
+	if i := int(yyActionBase[state]) + major; i >= 0 && i < len(yyCheck) && int(yyCheck[i]) == major {
+		return int(yyEntry[i])
+	}
+	return int(yyDefault[state])
+}
+
+// yyFindGoto returns the state entered after reducing to a nonterminal.
+func yyFindGoto(state, lhs int) int {
+	if i := int(yyGotoBase[state]) + lhs; i >= 0 && i < len(yyCheck) && int(yyCheck[i]) == lhs {
+		return int(yyEntry[i]) >> 2
+	}
+	return -1
+}
+
+var yyDefault = [...]{{.Tables.Type}}{{.Tables.Default}}
+
+var yyActionBase = [...]{{.Tables.Type}}{{.Tables.ActionBase}}
+
+var yyGotoBase = [...]{{.Tables.Type}}{{.Tables.GotoBase}}
+
+var yyEntry = [...]{{.Tables.Type}}{{.Tables.Entry}}
+
+var yyCheck = [...]{{.Tables.Type}}{{.Tables.Check}}
+{{else}}
+// yyFindAction returns the action for a lookahead terminal.
+func yyFindAction(state, major int) int {
+	return int(yyAction[state][major])
+}
+
+// yyFindGoto returns the state entered after reducing to a nonterminal.
+func yyFindGoto(state, lhs int) int {
+	return int(yyGoto[state][lhs-yyNumTerminals])
+}
+
+var yyAction = [...][yyNumTerminals]{{.Tables.Type}}{
+{{- range .Tables.Action}}
+	{{.}},
+{{- end}}
+}
+
+var yyGoto = [...][{{len .Symbols}} - yyNumTerminals]{{.Tables.Type}}{
+{{- range .Tables.Goto}}
+	{{.}},
+{{- end}}
+}
+{{end}}
+var yyRuleLHS = [...]{{.Tables.Type}}{{.Tables.RuleLHS}}
+
+var yyRuleSize = [...]{{.Tables.Type}}{{.Tables.RuleSize}}
+
+// yySymbolName names every symbol, by ID.
+var yySymbolName = [...]string{
+{{- range .Symbols}}
+	{{printf "%q" .Name}},
+{{- end}}
+}
+{{- if or .Trace .CST}}
+
+// yyRuleText gives every rule in grammar notation, by index.
+var yyRuleText = [...]string{
+{{- range .Rules}}
+	{{printf "%q" .Text}},
+{{- end}}
+}
+{{- end}}
+{{- if .Coverage}}
+
+// The coverage counters are shared by every parser, which may run in
+// several goroutines.
+var (
+	yyCoverRules  [{{len .Rules}}]atomic.Int64
+	yyCoverStates [{{.NumStates}}]atomic.Int64
+)
+
+// WriteCoverProfile writes the reductions and state entries counted so
+// far, in the format read by "guanabana coverage".
+func WriteCoverProfile(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "guanabana coverage v1\ngrammar %s\nautomaton %s %d\n", {{printf "%q" .Grammar}}, {{printf "%q" .Mode}}, len(yyCoverStates)); err != nil {
+		return err
+	}
+	for i := range yyCoverRules {
+		if n := yyCoverRules[i].Load(); n > 0 {
+			if _, err := fmt.Fprintf(w, "rule %d %d\n", i, n); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range yyCoverStates {
+		if n := yyCoverStates[i].Load(); n > 0 {
+			if _, err := fmt.Fprintf(w, "state %d %d\n", i, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+{{- end}}
+{{- if or .CodeComment .Code}}
+{{if .CodeComment}}
+{{.CodeComment}}
+{{- end}}
+{{- if .CodeLine}}
+{{.CodeLine}}
+{{- end}}
+{{.Code}}
+{{- end}}
+`
+
+// tokensTemplate writes the token file of GenerateTokens.
+var tokensTemplate = template.Must(template.New("tokens").Parse(tokensText))
+
+const tokensText = `// Code generated by guanabana from {{.Grammar}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import "strconv"
+
+// {{.TokenCode}} is the code of a token, as passed to the parser's Feed and
+// Parse. The end of the input is code 0.
+type {{.TokenCode}} int
+{{if .Tokens}}
+// Token codes.
+const (
+{{- range .Tokens}}
+	{{.Name}} {{$.TokenCode}} = {{.ID}}
+{{- end}}
+)
+{{end}}
+// String returns the token's name in the grammar.
+func (c {{.TokenCode}}) String() string {
+	if c >= 0 && int(c) < len(yyTokenName) {
+		return yyTokenName[c]
+	}
+	return "{{.TokenCode}}(" + strconv.Itoa(int(c)) + ")"
+}
+
+// yyTokenName names every terminal, by code.
+var yyTokenName = [...]string{
+{{- range .Symbols}}{{if .Terminal}}
+	{{printf "%q" .Name}},
+{{- end}}{{end}}
+}
+`
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCustomTemplate instruments every reduce through a template built
+// from the default one.
+func TestCustomTemplate(t *testing.T) {
+	a := buildAutomaton(t, calcGrammar)
+	text := strings.Replace(DefaultTemplate, "\tswitch rule {\n",
+		"\tyyReduced = append(yyReduced, yySymbolName[yyRuleLHS[rule]])\n\tswitch rule {\n", 1)
+	text += "\nvar yyReduced []string\n"
+	tmpl, err := ParseTemplate("reduce.tmpl", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y", Template: tmpl})
+	typeCheck(t, src)
+	if !bytes.Contains(src, []byte("yyReduced = append(yyReduced, yySymbolName[yyRuleLHS[rule]])")) {
+		t.Errorf("output lacks the instrumentation:\n%s", src)
+	}
+
+	tmpl, err = ParseTemplate("default", DefaultTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y"})
+	if got := generate(t, a, Config{Package: "calc", GrammarFile: "calc.y", Template: tmpl}); !bytes.Equal(got, want) {
+		t.Error("DefaultTemplate as a custom template changes the output")
+	}
+}
+
+// TestTemplateData checks the symbols of the data model.
+func TestTemplateData(t *testing.T) {
+	tmpl, err := ParseTemplate("symbols.tmpl", `package {{.Package}}
+{{range .Symbols}}
+// {{.ID}} {{.Name}} {{.Terminal}} {{.Type}} {{.Field}}
+{{- end}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(generate(t, buildAutomaton(t, calcGrammar), Config{GrammarFile: "calc.y", Template: tmpl}))
+	for _, want := range []string{
+		"// 0 $ true int yy0\n",
+		"// 1 PLUS true int yy0\n",
+		"list false []int yy1\n",
+		"expr false int yy0\n",
+		"// 10 $accept false\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("output lacks %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := ParseTemplate("bad.tmpl", "{{.Parser"); err == nil || !strings.Contains(err.Error(), "bad.tmpl:1") {
+		t.Errorf("parse: %v", err)
+	}
+	a := buildAutomaton(t, calcGrammar)
+	for text, want := range map[string]string{
+		"{{.Nope}}":                    "can't evaluate field Nope",
+		"package x\nfunc {{.Parser}}(": "generated parser is not valid Go",
+	} {
+		tmpl, err := ParseTemplate("bad.tmpl", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := Generate(&buf, a, Config{GrammarFile: "calc.y", Template: tmpl}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: %v", text, err)
+		}
+	}
+}
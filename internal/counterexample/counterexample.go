@@ -0,0 +1,196 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package counterexample explains parser conflicts with example input.
+//
+// For a conflict between two items in a state, it searches the automaton
+// for an input prefix that reaches the state with both items live and the
+// conflict terminal next. If the two continuations after that point can
+// derive a common sentential form, the example is unifying: one input with
+// two parses. Otherwise the example is non-unifying and shows the input on
+// which each item would act. This follows the approach of Isradisaikul and
+// Myers ("Finding Counterexamples from Parsing Conflicts", 2015), with
+// simple bounded searches in place of their cost model.
+package counterexample
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// maxTries bounds the number of stack pairs tried for unification.
+const maxTries = 50
+
+// Example is a counterexample for one conflict.
+type Example struct {
+	Conflict lalr.Conflict
+	// Unifying is true if both derivations derive the same symbols.
+	Unifying bool
+	// Items are the conflicting items; for a shift/reduce conflict the
+	// shift item comes first.
+	Items [2]lalr.Item
+	// Derivations are the derivation trees of the start symbol for each
+	// item, with the conflict point marked.
+	Derivations [2]*Derivation
+}
+
+// Find returns a counterexample for a conflict of the automaton.
+func Find(a *lalr.Automaton, c lalr.Conflict) (*Example, error) {
+	g := a.Grammar
+	ex := &Example{Conflict: c}
+	if c.Kind == lalr.ShiftReduce {
+		ex.Items = [2]lalr.Item{c.ShiftItem.Item, c.ReduceItem.Item}
+	} else {
+		ex.Items = [2]lalr.Item{c.ReduceItem.Item, c.ReduceItem2.Item}
+	}
+	s := newSearcher(a, c.Terminal)
+
+	// Find a prefix that reaches the conflict with both items live, then
+	// look for a pair of stacks whose continuations unify. The quick search
+	// settles most conflicts; the exact one tries the other stacks.
+	var first *node
+	try := func(nd *node) bool {
+		if first == nil {
+			first = nd
+		}
+		var slots [2][]*Derivation
+		var trees [2]*Derivation
+		for k := range ex.Items {
+			trees[k], slots[k] = buildTree(g, nd.stacks[k])
+		}
+		steps, ok := unify(g, symbolsOf(slots[0]), symbolsOf(slots[1]))
+		if ok {
+			for k := range slots {
+				apply(steps[k], slots[k])
+			}
+			ex.Derivations, ex.Unifying = trees, true
+		}
+		return ok
+	}
+	s.find(c.StateID, ex.Items, 2, false, try)
+	if !ex.Unifying && first != nil {
+		tries := 0
+		s.find(c.StateID, ex.Items, 2, true, func(nd *node) bool {
+			tries++
+			return try(nd) || tries >= maxTries
+		})
+	}
+	if ex.Unifying {
+		return ex, nil
+	} else if first != nil {
+		for k := range ex.Items {
+			ex.Derivations[k], _ = buildTree(g, first.stacks[k])
+		}
+		return ex, nil
+	}
+
+	// No common prefix reaches the conflict with both items live (typical
+	// of conflicts caused by LALR merging); show each item on its own.
+	for k, item := range ex.Items {
+		var end *node
+		s.find(c.StateID, [2]lalr.Item{item}, 1, false, func(nd *node) bool {
+			end = nd
+			return true
+		})
+		if end == nil {
+			return nil, fmt.Errorf("state %d: no path to %s", c.StateID, item.String(g))
+		}
+		ex.Derivations[k], _ = buildTree(g, end.stacks[0])
+	}
+	return ex, nil
+}
+
+// Write prints the example, e.g.
+//
+//	Example: expr PLUS expr • PLUS expr
+//	Shift derivation using calc.y:3:1: expr ::= expr PLUS expr.
+//	  expr ::= [expr PLUS expr ::= [expr • PLUS expr]]
+//	Reduce derivation using calc.y:3:1: expr ::= expr PLUS expr.
+//	  expr ::= [expr ::= [expr PLUS expr •] PLUS expr]
+func (ex *Example) Write(w io.Writer, g *grammar.Grammar) error {
+	labels := [2]string{"Shift derivation", "Reduce derivation"}
+	if ex.Conflict.Kind == lalr.ReduceReduce {
+		labels = [2]string{"First reduce derivation", "Second reduce derivation"}
+	}
+	examples := [2]string{"First example", "Second example"}
+	var err error
+	printf := func(format string, args ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	if ex.Unifying {
+		printf("Example: %s\n", ex.Derivations[0].Leaves())
+	}
+	for k, d := range ex.Derivations {
+		if !ex.Unifying {
+			printf("%s: %s\n", examples[k], d.Leaves())
+		}
+		r := g.Rules[ex.Items[k].RuleIndex]
+		printf("%s using %s: %s\n", labels[k], r.Pos, r)
+		printf("  %s\n", d)
+	}
+	return err
+}
+
+// buildTree builds the derivation tree for a stack of items, from the
+// $accept item inwards, with the conflict point after the innermost
+// item's dot. It returns the root (the start symbol's subtree) and the
+// leaves that follow the conflict point, in order.
+func buildTree(g *grammar.Grammar, stack []lalr.Item) (*Derivation, []*Derivation) {
+	var inner *Derivation
+	var slots []*Derivation
+	for k := len(stack) - 1; k >= 0; k-- {
+		item := stack[k]
+		r := g.Rules[item.RuleIndex]
+		d := &Derivation{Symbol: r.LHS, Rule: r}
+		d.Children = leaves(r.RHS[:item.Dot])
+		rest := r.RHS[item.Dot:]
+		if inner == nil {
+			d.Children = append(d.Children, &Derivation{})
+		} else {
+			d.Children = append(d.Children, inner)
+			rest = rest[1:]
+		}
+		after := leaves(rest)
+		d.Children = append(d.Children, after...)
+		slots = append(slots, after...)
+		inner = d
+	}
+	if inner.Rule == g.AcceptRule && !inner.Children[0].IsDot() {
+		inner = inner.Children[0]
+	}
+	return inner, slots
+}
+
+func leaves(symbols []*grammar.Symbol) []*Derivation {
+	list := make([]*Derivation, 0, len(symbols))
+	for _, s := range symbols {
+		list = append(list, &Derivation{Symbol: s})
+	}
+	return list
+}
+
+func symbolsOf(list []*Derivation) []*grammar.Symbol {
+	symbols := make([]*grammar.Symbol, 0, len(list))
+	for _, d := range list {
+		symbols = append(symbols, d.Symbol)
+	}
+	return symbols
+}
+
+// apply replays unification steps on the leaves after the conflict point:
+// a rule expands the leftmost pending leaf, nil leaves it as it is.
+func apply(steps []*grammar.Rule, pending []*Derivation) {
+	for _, r := range steps {
+		d := pending[0]
+		pending = pending[1:]
+		if r != nil {
+			d.Rule = r
+			d.Children = leaves(r.RHS)
+			pending = append(append([]*Derivation{}, d.Children...), pending...)
+		}
+	}
+}
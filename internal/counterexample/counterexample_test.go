@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package counterexample
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+// examples returns the written counterexample for every unresolved
+// conflict in the grammar's LALR automaton.
+func examples(t *testing.T, src string) []string {
+	t.Helper()
+	g := mustGrammar(t, src)
+	a := lalr.BuildLALR(g)
+	var list []string
+	for _, c := range lalr.DetectAndResolveConflicts(a, g).Conflicts {
+		if c.Resolved {
+			continue
+		}
+		ex, err := Find(a, c)
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		var sb strings.Builder
+		if err := ex.Write(&sb, g); err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, sb.String())
+	}
+	return list
+}
+
+func TestDanglingElse(t *testing.T) {
+	got := examples(t, `
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= X.
+expr ::= Y.
+`)
+	want := `Example: IF expr THEN IF expr THEN stmt • ELSE stmt
+Shift derivation using test.y:3:1: stmt ::= IF expr THEN stmt ELSE stmt.
+  stmt ::= [IF expr THEN stmt ::= [IF expr THEN stmt • ELSE stmt]]
+Reduce derivation using test.y:2:1: stmt ::= IF expr THEN stmt.
+  stmt ::= [IF expr THEN stmt ::= [IF expr THEN stmt •] ELSE stmt]
+`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
+
+func TestAmbiguousExpression(t *testing.T) {
+	got := examples(t, `
+expr ::= expr PLUS expr.
+expr ::= NUM.
+`)
+	want := `Example: expr PLUS expr • PLUS expr
+Shift derivation using test.y:2:1: expr ::= expr PLUS expr.
+  expr ::= [expr PLUS expr ::= [expr • PLUS expr]]
+Reduce derivation using test.y:2:1: expr ::= expr PLUS expr.
+  expr ::= [expr ::= [expr PLUS expr •] PLUS expr]
+`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
+
+// TestUnifyingWithExpansion needs the unification search to expand
+// nonterminals after the conflict point before the two sides agree.
+func TestUnifyingWithExpansion(t *testing.T) {
+	got := examples(t, `
+s ::= a B C.
+s ::= b B C.
+a ::= X.
+b ::= X.
+`)
+	want := `Example: X • B C
+First reduce derivation using test.y:4:1: a ::= X.
+  s ::= [a ::= [X •] B C]
+Second reduce derivation using test.y:5:1: b ::= X.
+  s ::= [b ::= [X •] B C]
+`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
+
+func TestNonUnifying(t *testing.T) {
+	got := examples(t, `
+s ::= x.
+s ::= y C.
+x ::= A C D.
+y ::= A.
+`)
+	want := `First example: A • C D
+Shift derivation using test.y:4:1: x ::= A C D.
+  s ::= [x ::= [A • C D]]
+Second example: A • C
+Reduce derivation using test.y:5:1: y ::= A.
+  s ::= [y ::= [A •] C]
+`
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
+
+// TestMergeConflict explains a conflict that only LALR merging creates:
+// no single prefix reaches it, so each item gets its own example.
+func TestMergeConflict(t *testing.T) {
+	got := examples(t, `
+s ::= A e C.
+s ::= A f D.
+s ::= B f C.
+s ::= B e D.
+e ::= E.
+f ::= E.
+`)
+	if len(got) != 2 {
+		t.Fatalf("%d examples, want 2", len(got))
+	}
+	want := `First example: A E • C
+First reduce derivation using test.y:6:1: e ::= E.
+  s ::= [A e ::= [E •] C]
+Second example: B E • C
+Second reduce derivation using test.y:7:1: f ::= E.
+  s ::= [B f ::= [E •] C]
+`
+	if got[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", got[0], want)
+	}
+}
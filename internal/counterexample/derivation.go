@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package counterexample
+
+import (
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// Derivation is a node of a derivation tree. An interior node has the rule
+// that expanded it; a leaf has no rule and stands for its symbol. A leaf
+// with no symbol marks the conflict point.
+type Derivation struct {
+	Symbol   *grammar.Symbol
+	Rule     *grammar.Rule
+	Children []*Derivation
+}
+
+// IsDot reports whether the node marks the conflict point.
+func (d *Derivation) IsDot() bool {
+	return d.Symbol == nil
+}
+
+// String renders the tree with each expanded nonterminal as
+// "name ::= [children]", e.g. "expr ::= [expr ::= [expr PLUS expr •] PLUS expr]".
+func (d *Derivation) String() string {
+	var sb strings.Builder
+	d.write(&sb)
+	return sb.String()
+}
+
+func (d *Derivation) write(sb *strings.Builder) {
+	switch {
+	case d.IsDot():
+		sb.WriteString("•")
+	case d.Rule == nil:
+		sb.WriteString(d.Symbol.Name)
+	default:
+		sb.WriteString(d.Symbol.Name)
+		sb.WriteString(" ::= [")
+		for i, c := range d.Children {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			c.write(sb)
+		}
+		sb.WriteByte(']')
+	}
+}
+
+// Leaves returns the symbols at the leaves of the tree, left to right,
+// with "•" at the conflict point.
+func (d *Derivation) Leaves() string {
+	var list []string
+	var walk func(d *Derivation)
+	walk = func(d *Derivation) {
+		switch {
+		case d.IsDot():
+			list = append(list, "•")
+		case d.Rule == nil:
+			list = append(list, d.Symbol.Name)
+		default:
+			for _, c := range d.Children {
+				walk(c)
+			}
+		}
+	}
+	walk(d)
+	return strings.Join(list, " ")
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package counterexample
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// maxSearch bounds the number of nodes a search may visit.
+const maxSearch = 100000
+
+// maxRepeat bounds how often one item may be entered by production steps
+// within a single state, which keeps left recursion from looping.
+const maxRepeat = 2
+
+// node is a search node for one or two items moving through the
+// automaton together. Each side has its stack of items, from the $accept
+// item inwards, and whether the conflict terminal can follow the LHS of
+// its innermost item.
+type node struct {
+	state   int
+	stacks  [2][]lalr.Item
+	follows [2]bool
+}
+
+// searcher finds paths in the state-item graph of an automaton. A path
+// starts at [$accept ::= * S] in state 0; a transition on X advances the
+// innermost item past X, and a production step in state s moves from
+// A ::= α * B β to B ::= * γ. Tracking whether the conflict terminal can
+// follow each innermost item means a reduce item is only reached in a
+// context where it would be reduced on that terminal.
+type searcher struct {
+	a        *lalr.Automaton
+	g        *grammar.Grammar
+	t        *grammar.Symbol
+	nullable map[int]bool
+	first    map[int]map[int]bool
+	byLHS    map[int][]*grammar.Rule
+}
+
+func newSearcher(a *lalr.Automaton, t *grammar.Symbol) *searcher {
+	g := a.Grammar
+	s := &searcher{a: a, g: g, t: t, byLHS: map[int][]*grammar.Rule{}}
+	s.nullable = analysis.ComputeNullable(g)
+	s.first = analysis.ComputeFirst(g, s.nullable)
+	for _, r := range g.Rules {
+		s.byLHS[r.LHS.ID] = append(s.byLHS[r.LHS.ID], r)
+	}
+	return s
+}
+
+// produce returns the nodes reached from nd by a production step on
+// side k.
+func (s *searcher) produce(nd *node, k int) []*node {
+	stack := nd.stacks[k]
+	top := stack[len(stack)-1]
+	b := top.SymbolAfterDot(s.g)
+	if b == nil || b.IsTerminal() {
+		return nil
+	}
+	rest := s.g.Rules[top.RuleIndex].RHS[top.Dot+1:]
+	follows, nullable := false, true
+	for _, x := range rest {
+		if s.first[x.ID][s.t.ID] {
+			follows = true
+		}
+		if !s.nullable[x.ID] {
+			nullable = false
+			break
+		}
+	}
+	follows = follows || nullable && nd.follows[k]
+
+	var list []*node
+	for _, r := range s.byLHS[b.ID] {
+		item := lalr.Item{RuleIndex: r.Index}
+		// Items entered in this state are the run of dot-0 items on top.
+		n := 0
+		for i := len(stack) - 1; i >= 0 && stack[i].Dot == 0; i-- {
+			if stack[i] == item {
+				n++
+			}
+		}
+		if n >= maxRepeat {
+			continue
+		}
+		next := &node{state: nd.state, stacks: nd.stacks, follows: nd.follows}
+		next.stacks[k] = append(stack[:len(stack):len(stack)], item)
+		next.follows[k] = follows
+		list = append(list, next)
+	}
+	return list
+}
+
+// find searches for paths that end in state target with the given items
+// innermost; with n == 1 only the first side moves. A reduce item must be
+// reached with the conflict terminal following it. Production steps are
+// free and transitions cost one, so goals with short prefixes come first.
+//
+// When exact is false, nodes are told apart by their innermost items
+// only, which finds one path quickly. When exact is true, they are told
+// apart by their whole stacks, so every way of reaching the goal is
+// found in turn. Each goal is passed to visit until it returns true.
+func (s *searcher) find(target int, items [2]lalr.Item, n int, exact bool, visit func(*node) bool) {
+	start := []lalr.Item{{RuleIndex: s.g.AcceptRule.Index}}
+	root := &node{stacks: [2][]lalr.Item{start, start}}
+	root.follows[0] = s.t == s.g.EOF
+	root.follows[1] = root.follows[0]
+	seen := map[string]bool{s.key(root, exact): true}
+
+	queue := list.New()
+	queue.PushBack(root)
+	push := func(nd *node, front bool) {
+		k := s.key(nd, exact)
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		if front {
+			queue.PushFront(nd)
+		} else {
+			queue.PushBack(nd)
+		}
+	}
+	for visited := 0; queue.Len() > 0 && visited < maxSearch; visited++ {
+		nd := queue.Remove(queue.Front()).(*node)
+		if nd.state == target && s.done(nd, items, n) && visit(nd) {
+			return
+		}
+		for k := 0; k < n; k++ {
+			for _, next := range s.produce(nd, k) {
+				push(next, true)
+			}
+		}
+		sym := s.top(nd, 0).SymbolAfterDot(s.g)
+		if sym == nil || n == 2 && s.top(nd, 1).SymbolAfterDot(s.g) != sym {
+			continue
+		}
+		to := s.a.Next(nd.state, sym)
+		if to < 0 {
+			continue
+		}
+		next := &node{state: to, stacks: nd.stacks, follows: nd.follows}
+		for k := 0; k < n; k++ {
+			stack := append([]lalr.Item(nil), nd.stacks[k]...)
+			stack[len(stack)-1].Dot++
+			next.stacks[k] = stack
+		}
+		push(next, false)
+	}
+}
+
+func (s *searcher) top(nd *node, k int) lalr.Item {
+	return nd.stacks[k][len(nd.stacks[k])-1]
+}
+
+func (s *searcher) done(nd *node, items [2]lalr.Item, n int) bool {
+	for k := 0; k < n; k++ {
+		item := s.top(nd, k)
+		if item != items[k] || item.IsReduce(s.g) && !nd.follows[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *searcher) key(nd *node, exact bool) string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(nd.state))
+	for k, stack := range nd.stacks {
+		if !exact {
+			stack = stack[len(stack)-1:]
+		}
+		sb.WriteString("|")
+		for _, item := range stack {
+			sb.WriteString(strconv.Itoa(item.RuleIndex))
+			sb.WriteByte('.')
+			sb.WriteString(strconv.Itoa(item.Dot))
+			sb.WriteByte(' ')
+		}
+		if nd.follows[k] {
+			sb.WriteByte('+')
+		}
+	}
+	return sb.String()
+}
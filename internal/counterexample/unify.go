@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package counterexample
+
+import (
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// maxUnify bounds the number of nodes the unification search may visit,
+// and maxForm the length of the sentential forms it considers.
+const (
+	maxUnify = 20000
+	maxForm  = 24
+)
+
+// uNode is a unification search node: the parts of the two sentential
+// forms not yet matched, and the step that led here.
+type uNode struct {
+	u, v   []*grammar.Symbol
+	parent *uNode
+	side   int           // side expanded, or -1 for a match
+	rule   *grammar.Rule // rule used by an expansion
+}
+
+// unify looks for a way to derive the same sentential form from u and v,
+// the two continuations after the conflict point. It expands the leftmost
+// symbol whenever the forms disagree and matches equal leading symbols.
+// On success it returns, for each side, the steps taken: a rule for an
+// expansion of that side's leftmost unmatched symbol, nil for a match.
+func unify(g *grammar.Grammar, u, v []*grammar.Symbol) ([2][]*grammar.Rule, bool) {
+	byLHS := map[int][]*grammar.Rule{}
+	for _, r := range g.Rules {
+		byLHS[r.LHS.ID] = append(byLHS[r.LHS.ID], r)
+	}
+	queue := []*uNode{{u: u, v: v, side: -1}}
+	for visited := 0; len(queue) > 0 && visited < maxUnify; visited++ {
+		nd := queue[0]
+		queue = queue[1:]
+		switch {
+		case len(nd.u) == 0 && len(nd.v) == 0:
+			return unifySteps(nd), true
+		case len(nd.u) > 0 && len(nd.v) > 0 && nd.u[0] == nd.v[0]:
+			queue = append(queue, &uNode{u: nd.u[1:], v: nd.v[1:], parent: nd, side: -1})
+			continue
+		}
+		k, form := 0, nd.u
+		if len(form) == 0 || form[0].IsTerminal() {
+			k, form = 1, nd.v
+		}
+		if len(form) == 0 || form[0].IsTerminal() {
+			continue // two different terminals, or a terminal against nothing
+		}
+		for _, r := range byLHS[form[0].ID] {
+			next := append(append([]*grammar.Symbol{}, r.RHS...), form[1:]...)
+			if len(next) > maxForm {
+				continue
+			}
+			child := &uNode{u: nd.u, v: nd.v, parent: nd, side: k, rule: r}
+			if k == 0 {
+				child.u = next
+			} else {
+				child.v = next
+			}
+			queue = append(queue, child)
+		}
+	}
+	return [2][]*grammar.Rule{}, false
+}
+
+// unifySteps lists the steps on the path to nd for each side.
+func unifySteps(nd *uNode) [2][]*grammar.Rule {
+	var path []*uNode
+	for ; nd.parent != nil; nd = nd.parent {
+		path = append(path, nd)
+	}
+	var steps [2][]*grammar.Rule
+	for i := len(path) - 1; i >= 0; i-- {
+		switch nd := path[i]; nd.side {
+		case -1:
+			steps[0] = append(steps[0], nil)
+			steps[1] = append(steps[1], nil)
+		default:
+			steps[nd.side] = append(steps[nd.side], nd.rule)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	p := NewProfile("calc.y")
+	p.Mode, p.NumStates = "ielr", 9
+	p.Rules[2] = 5
+	p.Rules[0] = 1
+	p.States[3] = 7
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "guanabana coverage v1\ngrammar calc.y\nautomaton ielr 9\nrule 0 1\nrule 2 5\nstate 3 7\n"
+	if buf.String() != want {
+		t.Errorf("Write = %q, want %q", buf.String(), want)
+	}
+
+	// Two concatenated runs add up.
+	q, err := ReadProfile(strings.NewReader(want + want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Grammar != "calc.y" || q.Mode != "ielr" || q.NumStates != 9 || q.Rules[2] != 10 || q.States[3] != 14 {
+		t.Errorf("ReadProfile = %+v", q)
+	}
+}
+
+func TestReadProfileErrors(t *testing.T) {
+	for _, src := range []string{
+		"rule 1 2\n",
+		"guanabana coverage v1\nrule x 2\n",
+		"guanabana coverage v1\nbogus 1 2\n",
+		"guanabana coverage v1\ngrammar a.y\ngrammar b.y\n",
+		"guanabana coverage v1\nautomaton lalr x\n",
+		"guanabana coverage v1\nautomaton lalr 9\nautomaton canonical 12\n",
+	} {
+		if _, err := ReadProfile(strings.NewReader(src)); err == nil {
+			t.Errorf("ReadProfile(%q): expected error", src)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	tokens, _ := lex.Tokenize("calc.y", []byte(`
+expr ::= expr PLUS NUM.
+expr ::= expr MINUS NUM.
+expr ::= NUM.
+`))
+	g, _, _ := grammar.ParseGrammar(tokens)
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	p := NewProfile("calc.y")
+	p.Rules[0] = 3
+	p.Rules[2] = 4
+	p.Rules[g.AcceptRule.Index] = 1
+	p.States[0] = 1
+	p.States[4] = 2
+
+	rep, err := NewReport(g, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Covered != 2 || len(rep.Rules) != 3 {
+		t.Errorf("covered %d of %d, want 2 of 3", rep.Covered, len(rep.Rules))
+	}
+	untested := rep.Untested()
+	if len(untested) != 1 || untested[0].Index != 1 {
+		t.Errorf("Untested = %v, want rule 1", untested)
+	}
+	if got, want := rep.Summary(), "coverage: 66.7% of rules (2/3), 2 states visited"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+	var buf bytes.Buffer
+	rep.WriteFunc(&buf)
+	if !strings.Contains(buf.String(), "calc.y:3:1:  expr ::= expr MINUS NUM.  0") {
+		t.Errorf("WriteFunc output missing untested rule:\n%s", buf.String())
+	}
+
+	p.Rules[9] = 1
+	if _, err := NewReport(g, p); err == nil {
+		t.Error("expected error for rule outside the grammar")
+	}
+	delete(p.Rules, 9)
+
+	// The states are counted in the automaton the profile records.
+	for _, mode := range []lalr.Mode{lalr.ModeLALR, lalr.ModeCanonical} {
+		n := len(lalr.Build(g, mode).States)
+		p.Mode, p.NumStates = mode.String(), n
+		rep, err := NewReport(g, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("2 of %d states visited", n); !strings.HasSuffix(rep.Summary(), want) {
+			t.Errorf("%s: Summary = %q, want suffix %q", mode, rep.Summary(), want)
+		}
+	}
+	p.NumStates++
+	if _, err := NewReport(g, p); err == nil {
+		t.Error("expected error for a profile with another number of states")
+	}
+	p.Mode, p.NumStates = "lalr", len(lalr.Build(g, lalr.ModeLALR).States)
+	p.States[p.NumStates] = 1
+	if _, err := NewReport(g, p); err == nil || !strings.Contains(err.Error(), "has state") {
+		t.Error("expected error for a state outside the automaton")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package coverage reads the rule and state coverage profiles written by
+// generated parsers and reports them against the grammar, in the spirit of
+// go test -cover.
+//
+// A profile is a line-oriented text file:
+//
+//	guanabana coverage v1
+//	grammar calc.y
+//	automaton lalr 15
+//	rule 3 12
+//	state 7 40
+//
+// The first line identifies the format. The grammar line names the grammar
+// file the parser was generated from. The automaton line gives the LR
+// construction of the parser (-lr) and its number of states, so state
+// numbers can be checked against the grammar. Each rule line gives a rule
+// index and the number of times it was reduced; each state line gives a
+// state number and the number of times it was entered. Profiles from
+// several test runs may be concatenated or merged; counts add.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header is the first line of every profile.
+const Header = "guanabana coverage v1"
+
+// Profile holds hit counts collected by a generated parser.
+type Profile struct {
+	Grammar   string      // grammar file the parser was generated from
+	Mode      string      // LR construction of the parser's automaton, or "" if unknown
+	NumStates int         // states in the parser's automaton, or 0 if unknown
+	Rules     map[int]int // reductions by rule index
+	States    map[int]int // entries by state number
+}
+
+// NewProfile returns an empty profile for the named grammar file.
+func NewProfile(grammarFile string) *Profile {
+	return &Profile{Grammar: grammarFile, Rules: map[int]int{}, States: map[int]int{}}
+}
+
+// ReadProfile parses one or more concatenated profiles, adding their counts.
+func ReadProfile(r io.Reader) (*Profile, error) {
+	p := NewProfile("")
+	sc := bufio.NewScanner(r)
+	lineNo, sawHeader := 0, false
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		} else if line == Header {
+			sawHeader = true
+			continue
+		} else if !sawHeader {
+			return nil, fmt.Errorf("coverage: line %d: missing %q header", lineNo, Header)
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "grammar":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("coverage: line %d: want \"grammar <file>\"", lineNo)
+			} else if p.Grammar != "" && p.Grammar != fields[1] {
+				return nil, fmt.Errorf("coverage: line %d: profile for %s mixed with %s", lineNo, fields[1], p.Grammar)
+			}
+			p.Grammar = fields[1]
+		case "automaton":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("coverage: line %d: want \"automaton <mode> <states>\"", lineNo)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("coverage: line %d: invalid number of states", lineNo)
+			} else if p.Mode != "" && (p.Mode != fields[1] || p.NumStates != n) {
+				return nil, fmt.Errorf("coverage: line %d: profile for a %s automaton with %d states mixed with %s with %d", lineNo, fields[1], n, p.Mode, p.NumStates)
+			}
+			p.Mode, p.NumStates = fields[1], n
+		case "rule", "state":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("coverage: line %d: want \"%s <index> <count>\"", lineNo, fields[0])
+			}
+			idx, err1 := strconv.Atoi(fields[1])
+			n, err2 := strconv.Atoi(fields[2])
+			if err1 != nil || err2 != nil || idx < 0 || n < 0 {
+				return nil, fmt.Errorf("coverage: line %d: invalid %s counts", lineNo, fields[0])
+			}
+			if fields[0] == "rule" {
+				p.Rules[idx] += n
+			} else {
+				p.States[idx] += n
+			}
+		default:
+			return nil, fmt.Errorf("coverage: line %d: unknown record %q", lineNo, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	} else if !sawHeader {
+		return nil, fmt.Errorf("coverage: missing %q header", Header)
+	}
+	return p, nil
+}
+
+// Merge adds the counts of other to p.
+func (p *Profile) Merge(other *Profile) error {
+	if p.Grammar == "" {
+		p.Grammar = other.Grammar
+	} else if other.Grammar != "" && other.Grammar != p.Grammar {
+		return fmt.Errorf("coverage: cannot merge profile for %s into %s", other.Grammar, p.Grammar)
+	}
+	if p.Mode == "" {
+		p.Mode, p.NumStates = other.Mode, other.NumStates
+	} else if other.Mode != "" && (other.Mode != p.Mode || other.NumStates != p.NumStates) {
+		return fmt.Errorf("coverage: cannot merge profile for a %s automaton with %d states into %s with %d", other.Mode, other.NumStates, p.Mode, p.NumStates)
+	}
+	for idx, n := range other.Rules {
+		p.Rules[idx] += n
+	}
+	for idx, n := range other.States {
+		p.States[idx] += n
+	}
+	return nil
+}
+
+// Write writes the profile with rules and states in index order.
+func (p *Profile) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, Header)
+	if p.Grammar != "" {
+		fmt.Fprintf(bw, "grammar %s\n", p.Grammar)
+	}
+	if p.Mode != "" {
+		fmt.Fprintf(bw, "automaton %s %d\n", p.Mode, p.NumStates)
+	}
+	for _, idx := range sortedKeys(p.Rules) {
+		fmt.Fprintf(bw, "rule %d %d\n", idx, p.Rules[idx])
+	}
+	for _, idx := range sortedKeys(p.States) {
+		fmt.Fprintf(bw, "state %d %d\n", idx, p.States[idx])
+	}
+	return bw.Flush()
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
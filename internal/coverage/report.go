@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// RuleHits is the hit count of one grammar rule.
+type RuleHits struct {
+	Rule *grammar.Rule
+	Hits int
+}
+
+// Report maps a profile back onto the grammar it was collected from.
+type Report struct {
+	Rules         []RuleHits // every rule except the augmented start rule, in rule order
+	Covered       int        // rules reduced at least once
+	StatesVisited int        // states entered at least once
+	NumStates     int        // states in the automaton, or 0 if unknown
+}
+
+// NewReport matches the profile's counts to the rules of g. When the
+// profile records its automaton, NewReport builds the automaton of g the
+// same way to count its states. It fails if the profile names rules or
+// states the grammar does not have, or was written by a parser whose
+// automaton has a different number of states.
+func NewReport(g *grammar.Grammar, p *Profile) (*Report, error) {
+	for idx := range p.Rules {
+		if idx >= len(g.Rules) {
+			return nil, fmt.Errorf("coverage: profile has rule %d but the grammar has %d rules; was the parser regenerated?", idx, len(g.Rules))
+		}
+	}
+	rep := &Report{}
+	if p.Mode != "" {
+		mode, err := lalr.ParseMode(p.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("coverage: %w", err)
+		}
+		rep.NumStates = len(lalr.Build(g, mode).States)
+		if rep.NumStates != p.NumStates {
+			return nil, fmt.Errorf("coverage: profile has %d states but the grammar's %s automaton has %d; was the parser regenerated?", p.NumStates, p.Mode, rep.NumStates)
+		}
+		for idx := range p.States {
+			if idx >= rep.NumStates {
+				return nil, fmt.Errorf("coverage: profile has state %d but the automaton has %d states", idx, rep.NumStates)
+			}
+		}
+	}
+	for _, r := range g.Rules {
+		if r == g.AcceptRule {
+			continue
+		}
+		hits := p.Rules[r.Index]
+		rep.Rules = append(rep.Rules, RuleHits{Rule: r, Hits: hits})
+		if hits > 0 {
+			rep.Covered++
+		}
+	}
+	for _, n := range p.States {
+		if n > 0 {
+			rep.StatesVisited++
+		}
+	}
+	return rep, nil
+}
+
+// Percent returns the percentage of rules covered.
+func (rep *Report) Percent() float64 {
+	if len(rep.Rules) == 0 {
+		return 100
+	}
+	return 100 * float64(rep.Covered) / float64(len(rep.Rules))
+}
+
+// Untested returns the rules that were never reduced, in rule order.
+func (rep *Report) Untested() []*grammar.Rule {
+	var list []*grammar.Rule
+	for _, rh := range rep.Rules {
+		if rh.Hits == 0 {
+			list = append(list, rh.Rule)
+		}
+	}
+	return list
+}
+
+// Summary returns a one-line summary such as
+// "coverage: 85.7% of rules (6/7), 12 of 15 states visited".
+func (rep *Report) Summary() string {
+	s := fmt.Sprintf("coverage: %.1f%% of rules (%d/%d)", rep.Percent(), rep.Covered, len(rep.Rules))
+	if rep.NumStates > 0 {
+		return s + fmt.Sprintf(", %d of %d states visited", rep.StatesVisited, rep.NumStates)
+	}
+	return s + fmt.Sprintf(", %d states visited", rep.StatesVisited)
+}
+
+// WriteFunc writes one line per rule with its position and hit count,
+// followed by the total, like go tool cover -func.
+func (rep *Report) WriteFunc(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, rh := range rep.Rules {
+		fmt.Fprintf(tw, "%s:\t%s\t%d\n", rh.Rule.Pos, rh.Rule, rh.Hits)
+	}
+	fmt.Fprintf(tw, "total:\t(rules)\t%.1f%%\n", rep.Percent())
+	return tw.Flush()
+}
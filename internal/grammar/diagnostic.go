@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"fmt"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// Severity of a diagnostic.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Diagnostic is a structured warning or error tied to a source position.
+type Diagnostic struct {
+	Pos      lex.Position
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos.IsZero() {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// HasErrors reports whether any diagnostic has SeverityError.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
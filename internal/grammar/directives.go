@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import "github.com/mdhender/guanabana/internal/lex"
+
+// DirectiveKind identifies a % directive in the grammar file.
+type DirectiveKind int
+
+const (
+	DirTokenType DirectiveKind = iota
+	DirType
+	DirDefaultType
+	DirStartSymbol
+	DirName
+	DirExtraArgument
+	DirInclude
+	DirTokenPrefix
+	DirCode
+	DirFallback
+	DirWildcard
+	DirDestructor
+	DirSyntaxError
+	DirParseAccept
+	DirParseFailure
+	DirStackOverflow
+	DirStackSize
+	DirLeft
+	DirRight
+	DirNonassoc
+	DirToken
+	DirTokenClass
+	DirTokenDestructor
+	DirDefaultDestructor
+	DirExtraContext
+	DirExpect
+	DirExpectRR
+	DirStackSizeLimit
+)
+
+var directiveNames = map[DirectiveKind]string{
+	DirTokenType:         "%token_type",
+	DirType:              "%type",
+	DirDefaultType:       "%default_type",
+	DirStartSymbol:       "%start_symbol",
+	DirName:              "%name",
+	DirExtraArgument:     "%extra_argument",
+	DirInclude:           "%include",
+	DirTokenPrefix:       "%token_prefix",
+	DirCode:              "%code",
+	DirFallback:          "%fallback",
+	DirWildcard:          "%wildcard",
+	DirDestructor:        "%destructor",
+	DirSyntaxError:       "%syntax_error",
+	DirParseAccept:       "%parse_accept",
+	DirParseFailure:      "%parse_failure",
+	DirStackOverflow:     "%stack_overflow",
+	DirStackSize:         "%stack_size",
+	DirLeft:              "%left",
+	DirRight:             "%right",
+	DirNonassoc:          "%nonassoc",
+	DirToken:             "%token",
+	DirTokenClass:        "%token_class",
+	DirTokenDestructor:   "%token_destructor",
+	DirDefaultDestructor: "%default_destructor",
+	DirExtraContext:      "%extra_context",
+	DirExpect:            "%expect",
+	DirExpectRR:          "%expect_rr",
+	DirStackSizeLimit:    "%stack_size_limit",
+}
+
+func (k DirectiveKind) String() string {
+	if s, ok := directiveNames[k]; ok {
+		return s
+	}
+	return "%unknown"
+}
+
+// Directive is a % directive as it appeared in the grammar file.
+type Directive struct {
+	Kind    DirectiveKind
+	Pos     lex.Position // position of the directive keyword
+	Symbols []string     // for directives that reference symbols
+	Aliases []string     // for %token, the "alias" of each symbol (may be empty)
+	Code    string       // for directives that have a code block, without the braces
+	CodePos lex.Position // position of the code block's opening brace
+	Value   string       // for directives with a simple value
+}
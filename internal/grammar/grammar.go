@@ -0,0 +1,248 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package grammar implements the in-memory model of a Lemon-style grammar,
+// the parser that builds it from a lex token stream, and its validation.
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EOFName is the name of the end-of-input marker, always symbol 0.
+	EOFName = "$"
+	// AcceptName is the name of the augmented start symbol.
+	AcceptName = "$accept"
+	// ErrorName is the name of the error pseudo-token used for recovery.
+	ErrorName = "error"
+)
+
+// Grammar holds the symbol table, the rules and the directives of a grammar.
+type Grammar struct {
+	Symbols    *SymbolTable
+	Rules      []*Rule
+	Start      *Symbol // set during finalization
+	EOF        *Symbol // the $ marker, always ID 0
+	Accept     *Symbol // the augmented start symbol, set during finalization
+	AcceptRule *Rule   // $accept ::= Start, set during finalization
+
+	Directives []Directive // in declaration order
+	PrecLevel  int         // highest precedence level assigned
+
+	finalized bool
+}
+
+// NewGrammar returns an empty grammar containing only the EOF marker.
+func NewGrammar() *Grammar {
+	g := &Grammar{Symbols: newSymbolTable()}
+	g.EOF = g.Symbols.AddTerminal(EOFName)
+	return g
+}
+
+// AddTerminal returns the terminal with the given name, creating it if needed.
+func (g *Grammar) AddTerminal(name string) *Symbol {
+	return g.Symbols.AddTerminal(name)
+}
+
+// AddNonterminal returns the nonterminal with the given name, creating it if needed.
+func (g *Grammar) AddNonterminal(name string) *Symbol {
+	return g.Symbols.AddNonterminal(name)
+}
+
+// AddRule appends a rule to the grammar. The LHS must be a nonterminal and
+// every RHS symbol must already exist in the symbol table.
+func (g *Grammar) AddRule(lhsName string, rhsNames []string, action string) (*Rule, error) {
+	lhs, ok := g.Symbols.Lookup(lhsName)
+	if !ok {
+		return nil, fmt.Errorf("rule LHS %q is not defined", lhsName)
+	} else if lhs.Kind != SymbolNonterminal {
+		return nil, fmt.Errorf("rule LHS %q must be a nonterminal", lhsName)
+	}
+	rhs := make([]*Symbol, 0, len(rhsNames))
+	for _, name := range rhsNames {
+		sym, ok := g.Symbols.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("rule %s: symbol %q is not defined", lhsName, name)
+		}
+		rhs = append(rhs, sym)
+	}
+	r := &Rule{
+		Index:      len(g.Rules),
+		LHS:        lhs,
+		RHS:        rhs,
+		Action:     action,
+		RHSAliases: make([]string, len(rhs)),
+	}
+	g.Rules = append(g.Rules, r)
+	return r, nil
+}
+
+// RulesFor returns all rules with nt as the LHS, in rule order.
+func (g *Grammar) RulesFor(nt *Symbol) []*Rule {
+	var rules []*Rule
+	for _, r := range g.Rules {
+		if r.LHS == nt {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// NumTerminals returns the number of terminals, including the EOF marker.
+// After Finalize, terminal IDs are 0 through NumTerminals()-1.
+func (g *Grammar) NumTerminals() int {
+	return g.Symbols.NumTerminals()
+}
+
+// DirectivesOf returns every directive of the given kind in declaration order.
+func (g *Grammar) DirectivesOf(kind DirectiveKind) []Directive {
+	var list []Directive
+	for _, d := range g.Directives {
+		if d.Kind == kind {
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
+// LastDirective returns the last directive of the given kind.
+// Single-valued directives follow Lemon's "last one wins" rule.
+func (g *Grammar) LastDirective(kind DirectiveKind) (Directive, bool) {
+	for i := len(g.Directives) - 1; i >= 0; i-- {
+		if g.Directives[i].Kind == kind {
+			return g.Directives[i], true
+		}
+	}
+	return Directive{}, false
+}
+
+// DirectiveValue returns the value of the last directive of the given kind.
+// For code directives the code is returned with surrounding space trimmed.
+func (g *Grammar) DirectiveValue(kind DirectiveKind) string {
+	d, ok := g.LastDirective(kind)
+	if !ok {
+		return ""
+	} else if d.Value != "" {
+		return d.Value
+	}
+	return strings.TrimSpace(d.Code)
+}
+
+// StackSize returns the value of %stack_size, or def if it was not given.
+func (g *Grammar) StackSize(def int) int {
+	if v := g.DirectiveValue(DirStackSize); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// StackSizeLimit returns the value of %stack_size_limit, or 0 if it was
+// not given.
+func (g *Grammar) StackSizeLimit() int {
+	if v := g.DirectiveValue(DirStackSizeLimit); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// ExpectedConflicts returns the conflict counts pinned by %expect and
+// %expect_rr. If only one of them is given, the other count is zero. ok is
+// false if neither is given.
+func (g *Grammar) ExpectedConflicts() (shiftReduce, reduceReduce int, ok bool) {
+	if v := g.DirectiveValue(DirExpect); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			shiftReduce, ok = n, true
+		}
+	}
+	if v := g.DirectiveValue(DirExpectRR); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			reduceReduce, ok = n, true
+		}
+	}
+	return shiftReduce, reduceReduce, ok
+}
+
+// Fallbacks maps every terminal listed after the first in a %fallback
+// directive to that first terminal, which the parser tries when the
+// terminal itself has no action.
+func (g *Grammar) Fallbacks() map[*Symbol]*Symbol {
+	fallbacks := map[*Symbol]*Symbol{}
+	for _, d := range g.DirectivesOf(DirFallback) {
+		to, ok := g.Symbols.Lookup(d.Symbols[0])
+		if !ok {
+			continue
+		}
+		for _, name := range d.Symbols[1:] {
+			if from, ok := g.Symbols.Lookup(name); ok {
+				fallbacks[from] = to
+			}
+		}
+	}
+	return fallbacks
+}
+
+// Wildcard returns the terminal named by %wildcard, which matches any
+// token that has no action of its own, or nil if there is none.
+func (g *Grammar) Wildcard() *Symbol {
+	d, ok := g.LastDirective(DirWildcard)
+	if !ok {
+		return nil
+	}
+	sym, _ := g.Symbols.Lookup(d.Symbols[0])
+	return sym
+}
+
+// Finalize selects the start symbol, renumbers the symbols so terminals
+// precede nonterminals, adds the augmented start rule and validates the
+// grammar. It returns an error if any diagnostic is an error.
+func (g *Grammar) Finalize() ([]Diagnostic, error) {
+	if g.finalized {
+		return nil, nil
+	}
+	var diags []Diagnostic
+	if d, ok := g.LastDirective(DirStartSymbol); ok {
+		sym, found := g.Symbols.Lookup(d.Value)
+		if !found || sym.Kind != SymbolNonterminal {
+			diags = append(diags, Diagnostic{Pos: d.Pos, Severity: SeverityError,
+				Message: fmt.Sprintf("start symbol %q is not a nonterminal of this grammar", d.Value)})
+		} else {
+			g.Start = sym
+		}
+	}
+	if g.Start == nil && len(g.Rules) > 0 && !HasErrors(diags) {
+		g.Start = g.Rules[0].LHS
+	}
+	if g.Start == nil {
+		if !HasErrors(diags) {
+			diags = append(diags, Diagnostic{Severity: SeverityError, Message: "grammar has no rules"})
+		}
+		return diags, fmt.Errorf("grammar: no start symbol")
+	}
+
+	g.Accept = g.AddNonterminal(AcceptName)
+	g.Symbols.renumber()
+	rule, err := g.AddRule(AcceptName, []string{g.Start.Name}, "")
+	if err != nil {
+		return diags, err
+	}
+	rule.Pos = g.Start.Pos
+	g.AcceptRule = rule
+	g.finalized = true
+
+	diags = append(diags, Validate(g)...)
+	if HasErrors(diags) {
+		return diags, fmt.Errorf("grammar: validation failed")
+	}
+	return diags, nil
+}
+
+// IsFinalized reports whether Finalize has augmented the grammar.
+func (g *Grammar) IsFinalized() bool {
+	return g.finalized
+}
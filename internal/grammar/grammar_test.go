@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import "testing"
+
+func TestSymbolTable(t *testing.T) {
+	g := NewGrammar()
+	plus := g.AddTerminal("PLUS")
+	minus := g.AddTerminal("MINUS")
+	expr := g.AddNonterminal("expr")
+
+	if plus.Kind != SymbolTerminal {
+		t.Error("PLUS should be terminal")
+	}
+	if expr.Kind != SymbolNonterminal {
+		t.Error("expr should be nonterminal")
+	}
+	if plus.ID == minus.ID || plus.ID == expr.ID {
+		t.Error("symbol IDs must be unique")
+	}
+	s, ok := g.Symbols.Lookup("PLUS")
+	if !ok || s != plus {
+		t.Error("Lookup(PLUS) failed")
+	}
+	if g.EOF == nil || g.EOF.ID != 0 {
+		t.Error("EOF marker should have ID 0")
+	}
+}
+
+func TestDuplicateSymbol(t *testing.T) {
+	g := NewGrammar()
+	s1 := g.AddTerminal("PLUS")
+	s2 := g.AddTerminal("PLUS")
+	if s1 != s2 {
+		t.Error("adding same terminal twice should return same symbol")
+	}
+}
+
+func TestAddRule(t *testing.T) {
+	g := NewGrammar()
+	g.AddTerminal("PLUS")
+	g.AddNonterminal("expr")
+	g.AddNonterminal("term")
+
+	r, err := g.AddRule("expr", []string{"expr", "PLUS", "term"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.LHS.Name != "expr" {
+		t.Error("LHS should be expr")
+	}
+	if len(r.RHS) != 3 {
+		t.Errorf("RHS length = %d, want 3", len(r.RHS))
+	}
+	if r.Index != 0 {
+		t.Errorf("first rule index = %d, want 0", r.Index)
+	}
+	if got, want := r.String(), "expr ::= expr PLUS term."; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := r.StringWithDot(1), "expr ::= expr * PLUS term."; got != want {
+		t.Errorf("StringWithDot(1) = %q, want %q", got, want)
+	}
+}
+
+func TestAddRuleUnknownSymbol(t *testing.T) {
+	g := NewGrammar()
+	g.AddNonterminal("expr")
+	_, err := g.AddRule("expr", []string{"UNKNOWN"}, "")
+	if err == nil {
+		t.Error("expected error for unknown symbol in RHS")
+	}
+}
+
+func TestAddRuleTerminalLHS(t *testing.T) {
+	g := NewGrammar()
+	g.AddTerminal("PLUS")
+	_, err := g.AddRule("PLUS", []string{}, "")
+	if err == nil {
+		t.Error("expected error for terminal as LHS")
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	g := NewGrammar()
+	g.AddTerminal("PLUS")
+	g.AddTerminal("NUM")
+	g.AddNonterminal("expr")
+	g.AddNonterminal("term")
+
+	g.AddRule("expr", []string{"expr", "PLUS", "term"}, "")
+	g.AddRule("expr", []string{"term"}, "")
+	g.AddRule("term", []string{"NUM"}, "")
+
+	expr, _ := g.Symbols.Lookup("expr")
+	rules := g.RulesFor(expr)
+	if len(rules) != 2 {
+		t.Errorf("RulesFor(expr) = %d rules, want 2", len(rules))
+	}
+}
+
+func TestFinalizeRenumbersTerminalsFirst(t *testing.T) {
+	g := NewGrammar()
+	g.AddNonterminal("expr")
+	g.AddTerminal("PLUS")
+	g.AddNonterminal("term")
+	g.AddTerminal("NUM")
+	g.AddRule("expr", []string{"expr", "PLUS", "term"}, "")
+	g.AddRule("expr", []string{"term"}, "")
+	g.AddRule("term", []string{"NUM"}, "")
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"$", "PLUS", "NUM", "expr", "term", "$accept"}
+	all := g.Symbols.All()
+	if len(all) != len(want) {
+		t.Fatalf("got %d symbols, want %d", len(all), len(want))
+	}
+	for i, name := range want {
+		if all[i].Name != name || all[i].ID != i {
+			t.Errorf("symbol %d = %s (id %d), want %s", i, all[i].Name, all[i].ID, name)
+		}
+	}
+	if g.NumTerminals() != 3 {
+		t.Errorf("NumTerminals = %d, want 3", g.NumTerminals())
+	}
+}
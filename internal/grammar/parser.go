@@ -0,0 +1,517 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// ParseGrammar reads a token stream and builds a Grammar.
+// It returns the grammar, any diagnostics collected, and a fatal error
+// if the input is completely unparseable. The token stream must end
+// with TOKEN_EOF, as returned by lex.Tokenize.
+func ParseGrammar(tokens []lex.Token) (*Grammar, []Diagnostic, error) {
+	if len(tokens) == 0 || tokens[len(tokens)-1].Type != lex.TOKEN_EOF {
+		return nil, nil, errors.New("grammar: token stream must end with TOKEN_EOF")
+	}
+	p := &parser{tokens: tokens, g: NewGrammar()}
+	for p.peek().Type != lex.TOKEN_EOF {
+		tok := p.peek()
+		switch {
+		case tok.Type == lex.TOKEN_NONTERMINAL:
+			p.parseRule()
+		case isDirective(tok.Type):
+			p.parseDirective()
+		default:
+			p.errorf(tok.Pos, "unexpected %s", describe(tok))
+			p.next()
+		}
+	}
+	p.assignPrecedence()
+	return p.g, p.diags, nil
+}
+
+type parser struct {
+	tokens []lex.Token
+	pos    int
+	g      *Grammar
+	diags  []Diagnostic
+}
+
+// peek returns the current token without consuming it.
+// The final TOKEN_EOF is returned forever once reached.
+func (p *parser) peek() lex.Token {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the current token.
+func (p *parser) next() lex.Token {
+	tok := p.tokens[p.pos]
+	if tok.Type != lex.TOKEN_EOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the current token if it has the given type.
+func (p *parser) accept(tt lex.TokenType) (lex.Token, bool) {
+	if p.peek().Type != tt {
+		return lex.Token{}, false
+	}
+	return p.next(), true
+}
+
+func (p *parser) errorf(pos lex.Position, format string, args ...any) {
+	p.diags = append(p.diags, Diagnostic{Pos: pos, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) warnf(pos lex.Position, format string, args ...any) {
+	p.diags = append(p.diags, Diagnostic{Pos: pos, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// recover skips tokens until just past the next dot, or until the start
+// of a directive or the end of input.
+func (p *parser) recover() {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Type == lex.TOKEN_EOF || isDirective(tok.Type):
+			return
+		case tok.Type == lex.TOKEN_DOT:
+			p.next()
+			return
+		}
+		p.next()
+	}
+}
+
+// parseRule parses lhs(ALIAS) ::= rhs(alias) ... . [PREC] { code }
+func (p *parser) parseRule() {
+	lhsTok := p.next()
+	lhsAlias, ok := p.parseAlias()
+	if !ok {
+		p.recover()
+		return
+	}
+	if _, ok := p.accept(lex.TOKEN_COLONCOLON_EQ); !ok {
+		p.errorf(p.peek().Pos, "expected ::= after %s, found %s", lhsTok.Literal, describe(p.peek()))
+		p.recover()
+		return
+	}
+
+	var rhs []lex.Token
+	var aliases []string
+	for {
+		tok := p.peek()
+		if tok.Type != lex.TOKEN_TERMINAL && tok.Type != lex.TOKEN_NONTERMINAL {
+			break
+		}
+		p.next()
+		alias, ok := p.parseAlias()
+		if !ok {
+			p.recover()
+			return
+		}
+		rhs = append(rhs, tok)
+		aliases = append(aliases, alias)
+	}
+	if _, ok := p.accept(lex.TOKEN_DOT); !ok {
+		p.errorf(p.peek().Pos, "expected '.' to end rule for %s, found %s", lhsTok.Literal, describe(p.peek()))
+		p.recover()
+		return
+	}
+
+	// Lemon puts the precedence mark before the action, but accept either order.
+	var precTok lex.Token
+	var codeTok lex.Token
+	for i := 0; i < 2; i++ {
+		switch p.peek().Type {
+		case lex.TOKEN_LBRACKET:
+			if precTok.Literal != "" {
+				break
+			}
+			open := p.next()
+			tok, ok := p.accept(lex.TOKEN_TERMINAL)
+			if !ok {
+				p.errorf(open.Pos, "expected terminal in precedence mark, found %s", describe(p.peek()))
+				p.recover()
+				return
+			}
+			if _, ok := p.accept(lex.TOKEN_RBRACKET); !ok {
+				p.errorf(p.peek().Pos, "expected ']' after precedence mark, found %s", describe(p.peek()))
+				p.recover()
+				return
+			}
+			precTok = tok
+		case lex.TOKEN_CODE_BLOCK:
+			if codeTok.Literal != "" {
+				break
+			}
+			codeTok = p.next()
+		}
+	}
+
+	if lhsTok.Literal == ErrorName {
+		p.errorf(lhsTok.Pos, "the %s symbol cannot appear on the left-hand side of a rule", ErrorName)
+		return
+	}
+	p.nonterminal(lhsTok)
+	names := make([]string, len(rhs))
+	for i, tok := range rhs {
+		p.symbol(tok)
+		names[i] = tok.Literal
+	}
+	rule, err := p.g.AddRule(lhsTok.Literal, names, codeText(codeTok.Literal))
+	if err != nil {
+		p.errorf(lhsTok.Pos, "%v", err)
+		return
+	}
+	rule.Pos = lhsTok.Pos
+	rule.LHSAlias = lhsAlias
+	copy(rule.RHSAliases, aliases)
+	if codeTok.Literal != "" {
+		rule.ActionPos = codeTok.Pos
+	}
+	if precTok.Literal != "" {
+		rule.PrecOverride = precTok.Literal
+		p.terminal(precTok)
+	}
+}
+
+// parseAlias parses an optional (ALIAS) after a symbol.
+// It returns false after reporting an error if the alias is malformed.
+func (p *parser) parseAlias() (string, bool) {
+	open, ok := p.accept(lex.TOKEN_LPAREN)
+	if !ok {
+		return "", true
+	}
+	tok := p.peek()
+	if tok.Type != lex.TOKEN_TERMINAL && tok.Type != lex.TOKEN_NONTERMINAL {
+		p.errorf(open.Pos, "expected alias name after '(', found %s", describe(tok))
+		return "", false
+	}
+	p.next()
+	if _, ok := p.accept(lex.TOKEN_RPAREN); !ok {
+		p.errorf(p.peek().Pos, "expected ')' after alias %s, found %s", tok.Literal, describe(p.peek()))
+		return "", false
+	}
+	return tok.Literal, true
+}
+
+// symbol registers a symbol from a rule's RHS. The lexer classifies
+// identifiers by case; "error" is lower case but is always a terminal.
+func (p *parser) symbol(tok lex.Token) *Symbol {
+	if tok.Type == lex.TOKEN_TERMINAL || tok.Literal == ErrorName {
+		return p.terminal(tok)
+	}
+	return p.nonterminal(tok)
+}
+
+func (p *parser) terminal(tok lex.Token) *Symbol {
+	s := p.g.AddTerminal(tok.Literal)
+	if s.Pos.IsZero() {
+		s.Pos = tok.Pos
+	}
+	return s
+}
+
+func (p *parser) nonterminal(tok lex.Token) *Symbol {
+	s := p.g.AddNonterminal(tok.Literal)
+	if s.Pos.IsZero() {
+		s.Pos = tok.Pos
+	}
+	return s
+}
+
+// codeDirectives maps directives that take a single code block.
+var codeDirectives = map[lex.TokenType]DirectiveKind{
+	lex.TOKEN_DIR_CODE:               DirCode,
+	lex.TOKEN_DIR_DEFAULT_DESTRUCTOR: DirDefaultDestructor,
+	lex.TOKEN_DIR_DEFAULT_TYPE:       DirDefaultType,
+	lex.TOKEN_DIR_EXTRA_ARGUMENT:     DirExtraArgument,
+	lex.TOKEN_DIR_EXTRA_CONTEXT:      DirExtraContext,
+	lex.TOKEN_DIR_INCLUDE:            DirInclude,
+	lex.TOKEN_DIR_PARSE_ACCEPT:       DirParseAccept,
+	lex.TOKEN_DIR_PARSE_FAILURE:      DirParseFailure,
+	lex.TOKEN_DIR_STACK_OVERFLOW:     DirStackOverflow,
+	lex.TOKEN_DIR_SYNTAX_ERROR:       DirSyntaxError,
+	lex.TOKEN_DIR_TOKEN_DESTRUCTOR:   DirTokenDestructor,
+	lex.TOKEN_DIR_TOKEN_TYPE:         DirTokenType,
+}
+
+// nameDirectives maps directives that take a single identifier.
+var nameDirectives = map[lex.TokenType]DirectiveKind{
+	lex.TOKEN_DIR_NAME:         DirName,
+	lex.TOKEN_DIR_START_SYMBOL: DirStartSymbol,
+	lex.TOKEN_DIR_TOKEN_PREFIX: DirTokenPrefix,
+}
+
+// precedenceDirectives maps %left, %right and %nonassoc.
+var precedenceDirectives = map[lex.TokenType]DirectiveKind{
+	lex.TOKEN_DIR_LEFT:     DirLeft,
+	lex.TOKEN_DIR_RIGHT:    DirRight,
+	lex.TOKEN_DIR_NONASSOC: DirNonassoc,
+}
+
+// integerDirectives maps directives that take a single integer.
+var integerDirectives = map[lex.TokenType]DirectiveKind{
+	lex.TOKEN_DIR_EXPECT:           DirExpect,
+	lex.TOKEN_DIR_EXPECT_RR:        DirExpectRR,
+	lex.TOKEN_DIR_STACK_SIZE:       DirStackSize,
+	lex.TOKEN_DIR_STACK_SIZE_LIMIT: DirStackSizeLimit,
+}
+
+func isDirective(tt lex.TokenType) bool {
+	return lex.TOKEN_DIR_CODE <= tt && tt <= lex.TOKEN_DIR_GENERIC
+}
+
+// parseDirective parses one % directive and records it on the grammar.
+func (p *parser) parseDirective() {
+	tok := p.next()
+	d := Directive{Pos: tok.Pos}
+	if kind, ok := codeDirectives[tok.Type]; ok {
+		d.Kind = kind
+		if !p.parseCode(tok, &d) {
+			return
+		}
+	} else if kind, ok := nameDirectives[tok.Type]; ok {
+		d.Kind = kind
+		name, ok := p.parseName(tok)
+		if !ok {
+			return
+		}
+		d.Value = name.Literal
+		p.accept(lex.TOKEN_DOT)
+	} else if kind, ok := precedenceDirectives[tok.Type]; ok {
+		d.Kind = kind
+		list, ok := p.parseTerminalList(tok)
+		if !ok {
+			return
+		}
+		d.Symbols = list
+	} else {
+		switch tok.Type {
+		case lex.TOKEN_DIR_TYPE, lex.TOKEN_DIR_DESTRUCTOR:
+			d.Kind = DirType
+			if tok.Type == lex.TOKEN_DIR_DESTRUCTOR {
+				d.Kind = DirDestructor
+			}
+			name, ok := p.parseName(tok)
+			if !ok {
+				return
+			}
+			d.Symbols = []string{name.Literal}
+			if !p.parseCode(tok, &d) {
+				return
+			}
+		case lex.TOKEN_DIR_STACK_SIZE, lex.TOKEN_DIR_STACK_SIZE_LIMIT, lex.TOKEN_DIR_EXPECT, lex.TOKEN_DIR_EXPECT_RR:
+			d.Kind = integerDirectives[tok.Type]
+			n, ok := p.accept(lex.TOKEN_INTEGER)
+			if !ok {
+				p.errorf(tok.Pos, "%s requires an integer, found %s", tok.Literal, describe(p.peek()))
+				p.recover()
+				return
+			}
+			d.Value = n.Literal
+			p.accept(lex.TOKEN_DOT)
+		case lex.TOKEN_DIR_WILDCARD:
+			d.Kind = DirWildcard
+			list, ok := p.parseTerminalList(tok)
+			if !ok {
+				return
+			} else if len(list) != 1 {
+				p.errorf(tok.Pos, "%s requires exactly one terminal", tok.Literal)
+				return
+			}
+			d.Symbols = list
+		case lex.TOKEN_DIR_FALLBACK:
+			d.Kind = DirFallback
+			list, ok := p.parseTerminalList(tok)
+			if !ok {
+				return
+			} else if len(list) < 2 {
+				p.errorf(tok.Pos, "%s requires a fallback terminal and at least one other terminal", tok.Literal)
+				return
+			}
+			d.Symbols = list
+		case lex.TOKEN_DIR_TOKEN_CLASS:
+			d.Kind = DirTokenClass
+			name, ok := p.parseName(tok)
+			if !ok {
+				return
+			}
+			d.Value = name.Literal
+			list, ok := p.parseTerminalList(tok)
+			if !ok {
+				return
+			}
+			d.Symbols = list
+			p.warnf(tok.Pos, "%s is not supported; %s is ignored", tok.Literal, name.Literal)
+		case lex.TOKEN_DIR_IFDEF, lex.TOKEN_DIR_IFNDEF, lex.TOKEN_DIR_ENDIF:
+			p.warnf(tok.Pos, "%s is not supported; both branches are processed", tok.Literal)
+			if tok.Type != lex.TOKEN_DIR_ENDIF {
+				p.parseName(tok)
+			}
+			return
+		case lex.TOKEN_DIR_GENERIC:
+			if tok.Literal != "%token" {
+				p.errorf(tok.Pos, "unknown directive %s", tok.Literal)
+				p.recover()
+				return
+			}
+			d.Kind = DirToken
+			if !p.parseTokenList(tok, &d) {
+				return
+			}
+		default:
+			p.errorf(tok.Pos, "unexpected %s", describe(tok))
+			return
+		}
+	}
+	p.g.Directives = append(p.g.Directives, d)
+}
+
+// parseCode reads the code block argument of a directive into d.
+func (p *parser) parseCode(dir lex.Token, d *Directive) bool {
+	code, ok := p.accept(lex.TOKEN_CODE_BLOCK)
+	if !ok {
+		p.errorf(dir.Pos, "%s requires a code block, found %s", dir.Literal, describe(p.peek()))
+		p.recover()
+		return false
+	}
+	d.Code = codeText(code.Literal)
+	d.CodePos = code.Pos
+	return true
+}
+
+// parseName reads the identifier argument of a directive.
+func (p *parser) parseName(dir lex.Token) (lex.Token, bool) {
+	tok := p.peek()
+	if tok.Type != lex.TOKEN_TERMINAL && tok.Type != lex.TOKEN_NONTERMINAL {
+		p.errorf(dir.Pos, "%s requires a name, found %s", dir.Literal, describe(tok))
+		p.recover()
+		return tok, false
+	}
+	return p.next(), true
+}
+
+// parseTerminalList reads terminals up to and including the terminating dot.
+func (p *parser) parseTerminalList(dir lex.Token) ([]string, bool) {
+	var list []string
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case lex.TOKEN_DOT:
+			p.next()
+			return list, true
+		case lex.TOKEN_TERMINAL:
+			p.next()
+			p.terminal(tok)
+			list = append(list, tok.Literal)
+		default:
+			p.errorf(tok.Pos, "%s expects terminals followed by '.', found %s", dir.Literal, describe(tok))
+			p.recover()
+			return nil, false
+		}
+	}
+}
+
+// parseTokenList reads the arguments of %token: terminals, each optionally
+// followed by a "string" alias, up to and including the terminating dot.
+func (p *parser) parseTokenList(dir lex.Token, d *Directive) bool {
+	for {
+		tok := p.peek()
+		switch tok.Type {
+		case lex.TOKEN_DOT:
+			p.next()
+			return true
+		case lex.TOKEN_TERMINAL:
+			p.next()
+			sym := p.terminal(tok)
+			alias := ""
+			if str, ok := p.accept(lex.TOKEN_STRING); ok {
+				text, err := strconv.Unquote(str.Literal)
+				if err != nil {
+					p.errorf(str.Pos, "invalid alias %s for %s: %v", str.Literal, tok.Literal, err)
+				} else {
+					alias = text
+					sym.Alias = text
+				}
+			}
+			d.Symbols = append(d.Symbols, tok.Literal)
+			d.Aliases = append(d.Aliases, alias)
+		default:
+			p.errorf(tok.Pos, "%s expects terminals followed by '.', found %s", dir.Literal, describe(tok))
+			p.recover()
+			return false
+		}
+	}
+}
+
+// assignPrecedence applies %left, %right and %nonassoc to terminals in
+// declaration order, then gives every rule the precedence of its [TOKEN]
+// override or of its rightmost terminal.
+func (p *parser) assignPrecedence() {
+	g := p.g
+	for _, d := range g.Directives {
+		var assoc Assoc
+		switch d.Kind {
+		case DirLeft:
+			assoc = AssocLeft
+		case DirRight:
+			assoc = AssocRight
+		case DirNonassoc:
+			assoc = AssocNonassoc
+		default:
+			continue
+		}
+		g.PrecLevel++
+		for _, name := range d.Symbols {
+			sym, _ := g.Symbols.Lookup(name)
+			if !sym.Precedence.IsZero() {
+				p.errorf(d.Pos, "symbol %s has already been given a precedence", name)
+				continue
+			}
+			sym.Precedence = Precedence{Level: g.PrecLevel, Assoc: assoc}
+		}
+	}
+	for _, r := range g.Rules {
+		if r.PrecOverride != "" {
+			sym, _ := g.Symbols.Lookup(r.PrecOverride)
+			r.Precedence = sym.Precedence
+			if sym.Precedence.IsZero() {
+				p.warnf(r.Pos, "precedence mark [%s] on rule %s has no precedence", sym.Name, r)
+			}
+			continue
+		}
+		for i := len(r.RHS) - 1; i >= 0; i-- {
+			if r.RHS[i].Kind == SymbolTerminal {
+				r.Precedence = r.RHS[i].Precedence
+				break
+			}
+		}
+	}
+}
+
+// codeText strips the braces from a code block literal.
+func codeText(lit string) string {
+	if len(lit) >= 2 && lit[0] == '{' && lit[len(lit)-1] == '}' {
+		return lit[1 : len(lit)-1]
+	}
+	return lit
+}
+
+// describe returns a short description of a token for diagnostics.
+func describe(tok lex.Token) string {
+	switch tok.Type {
+	case lex.TOKEN_EOF:
+		return "end of file"
+	case lex.TOKEN_CODE_BLOCK:
+		return "code block"
+	}
+	return fmt.Sprintf("%q", tok.Literal)
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestParseSingleRule(t *testing.T) {
+	src := []byte("expr ::= expr PLUS term.")
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+	if len(g.Rules) != 1 {
+		t.Fatalf("got %d rules, want 1", len(g.Rules))
+	}
+	r := g.Rules[0]
+	if r.LHS.Name != "expr" {
+		t.Errorf("LHS = %q, want %q", r.LHS.Name, "expr")
+	}
+	if len(r.RHS) != 3 {
+		t.Errorf("RHS len = %d, want 3", len(r.RHS))
+	}
+	if r.Pos.String() != "test.y:1:1" {
+		t.Errorf("Pos = %s, want test.y:1:1", r.Pos)
+	}
+}
+
+func TestParseMultipleRules(t *testing.T) {
+	src := []byte(`
+expr ::= expr PLUS term.
+expr ::= term.
+term ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+	if len(g.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(g.Rules))
+	}
+}
+
+func TestParseRuleWithAliases(t *testing.T) {
+	src := []byte("expr(A) ::= expr(B) PLUS term(C). { A = B + C; }")
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := g.Rules[0]
+	if r.LHSAlias != "A" {
+		t.Errorf("LHSAlias = %q, want %q", r.LHSAlias, "A")
+	}
+	if want := []string{"B", "", "C"}; len(r.RHSAliases) != 3 || r.RHSAliases[0] != want[0] || r.RHSAliases[1] != want[1] || r.RHSAliases[2] != want[2] {
+		t.Errorf("RHSAliases = %q, want %q", r.RHSAliases, want)
+	}
+	if r.Action != " A = B + C; " {
+		t.Errorf("Action = %q, want %q", r.Action, " A = B + C; ")
+	}
+	if r.ActionPos.Column != 35 {
+		t.Errorf("ActionPos = %s, want column 35", r.ActionPos)
+	}
+}
+
+func TestDirectiveRecognized(t *testing.T) {
+	src := []byte(`
+%left PLUS MINUS.
+expr ::= term.
+term ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			t.Errorf("unexpected error: %v", d)
+		}
+	}
+	if len(g.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(g.Rules))
+	}
+}
+
+func TestAutoCreateSymbols(t *testing.T) {
+	src := []byte("expr ::= expr PLUS term.")
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+
+	_, ok := g.Symbols.Lookup("PLUS")
+	if !ok {
+		t.Error("PLUS should be auto-created as terminal")
+	}
+	s, ok := g.Symbols.Lookup("expr")
+	if !ok {
+		t.Error("expr should be auto-created as nonterminal")
+	}
+	if s.Kind != SymbolNonterminal {
+		t.Error("expr should be nonterminal")
+	}
+}
+
+func TestMissingDotDiagnostic(t *testing.T) {
+	src := []byte("expr ::= term")
+	tokens, _ := lex.Tokenize("test.y", src)
+	_, diags, _ := ParseGrammar(tokens)
+	found := false
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected error diagnostic for missing dot")
+	}
+}
+
+func TestRecoveryAfterBadRule(t *testing.T) {
+	src := []byte(`
+expr ::= term ( .
+expr ::= term.
+term ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasErrors(diags) {
+		t.Error("expected an error for the malformed rule")
+	}
+	if len(g.Rules) != 2 {
+		t.Errorf("got %d rules, want 2 after recovery", len(g.Rules))
+	}
+}
+
+func TestTokenDirectiveAliases(t *testing.T) {
+	src := []byte(`
+%token PLUS "+" NUM.
+%stack_size 50
+%stack_size_limit 5000.
+expr ::= expr PLUS NUM.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+	plus, _ := g.Symbols.Lookup("PLUS")
+	if plus.Alias != "+" || plus.DisplayName() != "+" {
+		t.Errorf("PLUS alias = %q, want %q", plus.Alias, "+")
+	}
+	num, _ := g.Symbols.Lookup("NUM")
+	if num.DisplayName() != "NUM" {
+		t.Errorf("NUM display name = %q, want %q", num.DisplayName(), "NUM")
+	}
+	if n := g.StackSize(100); n != 50 {
+		t.Errorf("StackSize = %d, want 50", n)
+	}
+	if n := g.StackSizeLimit(); n != 5000 {
+		t.Errorf("StackSizeLimit = %d, want 5000", n)
+	}
+}
+
+func TestExpectDirectives(t *testing.T) {
+	for _, tc := range []struct {
+		src    string
+		sr, rr int
+		ok     bool
+	}{
+		{"", 0, 0, false},
+		{"%expect 1.", 1, 0, true},
+		{"%expect_rr 2.", 0, 2, true},
+		{"%expect 3. %expect_rr 4.", 3, 4, true},
+	} {
+		src := []byte(tc.src + "\nstmt ::= NUM.\n")
+		tokens, _ := lex.Tokenize("test.y", src)
+		g, diags, err := ParseGrammar(tokens)
+		if err != nil || len(diags) > 0 {
+			t.Fatalf("%q: err = %v, diags = %v", tc.src, err, diags)
+		}
+		sr, rr, ok := g.ExpectedConflicts()
+		if sr != tc.sr || rr != tc.rr || ok != tc.ok {
+			t.Errorf("%q: ExpectedConflicts = %d, %d, %v, want %d, %d, %v", tc.src, sr, rr, ok, tc.sr, tc.rr, tc.ok)
+		}
+	}
+}
+
+func TestExpectRequiresInteger(t *testing.T) {
+	tokens, _ := lex.Tokenize("test.y", []byte("%expect many.\nstmt ::= NUM.\n"))
+	_, diags, _ := ParseGrammar(tokens)
+	if !HasErrors(diags) {
+		t.Error("expected an error for a non-integer expect count")
+	}
+}
+
+func TestErrorIsTerminal(t *testing.T) {
+	src := []byte(`
+stmt ::= expr SEMI.
+stmt ::= error SEMI.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	sym, ok := g.Symbols.Lookup(ErrorName)
+	if !ok || sym.Kind != SymbolTerminal {
+		t.Errorf("%s should be a terminal", ErrorName)
+	}
+	if _, err := g.Finalize(); err != nil {
+		t.Errorf("Finalize: %v", err)
+	}
+}
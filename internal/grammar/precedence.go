@@ -0,0 +1,37 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+// Assoc is the associativity of a terminal or rule.
+type Assoc int
+
+const (
+	AssocNone     Assoc = iota // no associativity assigned
+	AssocLeft                  // %left
+	AssocRight                 // %right
+	AssocNonassoc              // %nonassoc
+)
+
+func (a Assoc) String() string {
+	switch a {
+	case AssocNone:
+		return "none"
+	case AssocLeft:
+		return "left"
+	case AssocRight:
+		return "right"
+	case AssocNonassoc:
+		return "nonassoc"
+	}
+	return "unknown"
+}
+
+// Precedence is the binding strength of a terminal or rule.
+// Level 0 means no precedence was assigned; 1 is the lowest.
+type Precedence struct {
+	Level int
+	Assoc Assoc
+}
+
+// IsZero reports whether no precedence has been assigned.
+func (p Precedence) IsZero() bool { return p.Level == 0 }
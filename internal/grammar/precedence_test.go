@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestPrecedenceLevels(t *testing.T) {
+	src := []byte(`
+%left PLUS MINUS.
+%left TIMES DIVIDE.
+%right EXP.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, diags, err := ParseGrammar(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) > 0 {
+		t.Errorf("diagnostics: %v", diags)
+	}
+
+	plus, _ := g.Symbols.Lookup("PLUS")
+	times, _ := g.Symbols.Lookup("TIMES")
+	exp, _ := g.Symbols.Lookup("EXP")
+
+	if plus.Precedence.Level != 1 {
+		t.Errorf("PLUS level = %d, want 1", plus.Precedence.Level)
+	}
+	if plus.Precedence.Assoc != AssocLeft {
+		t.Errorf("PLUS assoc = %v, want Left", plus.Precedence.Assoc)
+	}
+	if times.Precedence.Level != 2 {
+		t.Errorf("TIMES level = %d, want 2", times.Precedence.Level)
+	}
+	if exp.Precedence.Level != 3 {
+		t.Errorf("EXP level = %d, want 3", exp.Precedence.Level)
+	}
+	if exp.Precedence.Assoc != AssocRight {
+		t.Errorf("EXP assoc = %v, want Right", exp.Precedence.Assoc)
+	}
+}
+
+func TestRulePrecedenceRightmostTerminal(t *testing.T) {
+	src := []byte(`
+%left PLUS.
+%left TIMES.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+
+	if g.Rules[0].Precedence.Level != 1 {
+		t.Errorf("rule 0 prec level = %d, want 1", g.Rules[0].Precedence.Level)
+	}
+	if g.Rules[1].Precedence.Level != 2 {
+		t.Errorf("rule 1 prec level = %d, want 2", g.Rules[1].Precedence.Level)
+	}
+	if g.Rules[2].Precedence.Level != 0 {
+		t.Errorf("rule 2 prec level = %d, want 0", g.Rules[2].Precedence.Level)
+	}
+}
+
+func TestPrecedenceOverride(t *testing.T) {
+	src := []byte(`
+%left PLUS.
+%left TIMES.
+%left UMINUS.
+expr ::= MINUS expr. [UMINUS]
+expr ::= expr PLUS expr.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+
+	if g.Rules[0].Precedence.Level != 3 {
+		t.Errorf("override rule prec = %d, want 3", g.Rules[0].Precedence.Level)
+	}
+}
+
+func TestTokenTypeDirective(t *testing.T) {
+	src := []byte(`
+%token_type { Value }
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+
+	found := false
+	for _, d := range g.Directives {
+		if d.Kind == DirTokenType {
+			found = true
+			if d.Code == "" {
+				t.Error("token_type directive should have code")
+			}
+		}
+	}
+	if !found {
+		t.Error("token_type directive not found")
+	}
+	if v := g.DirectiveValue(DirTokenType); v != "Value" {
+		t.Errorf("DirectiveValue(DirTokenType) = %q, want %q", v, "Value")
+	}
+}
+
+func TestSamePrecedenceLine(t *testing.T) {
+	src := []byte(`
+%left PLUS MINUS.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+
+	plus, _ := g.Symbols.Lookup("PLUS")
+	minus, _ := g.Symbols.Lookup("MINUS")
+
+	if plus.Precedence.Level != minus.Precedence.Level {
+		t.Error("PLUS and MINUS should have same precedence level")
+	}
+	if plus.Precedence.Assoc != minus.Precedence.Assoc {
+		t.Error("PLUS and MINUS should have same associativity")
+	}
+}
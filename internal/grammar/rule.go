@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// Rule is a production LHS ::= RHS₁ RHS₂ … RHSₙ.
+type Rule struct {
+	Index  int       // position in the grammar's rule list
+	LHS    *Symbol   // left-hand side nonterminal
+	RHS    []*Symbol // right-hand side symbols (may be empty for ε-rules)
+	Action string    // Go code for the reduce action, without the braces (may be empty)
+
+	Pos       lex.Position // position of the LHS symbol
+	ActionPos lex.Position // position of the action's opening brace
+
+	LHSAlias   string   // alias from lhs(A), or ""
+	RHSAliases []string // alias for each RHS symbol, or ""
+
+	Precedence   Precedence
+	PrecOverride string // terminal named in a [TOKEN] override, or ""
+}
+
+// String returns the rule in grammar-file notation, e.g. "expr ::= expr PLUS term."
+func (r *Rule) String() string {
+	return r.StringWithDot(-1)
+}
+
+// StringWithDot returns the rule with a "*" marker before RHS position dot.
+// A negative dot omits the marker.
+func (r *Rule) StringWithDot(dot int) string {
+	var sb strings.Builder
+	sb.WriteString(r.LHS.Name)
+	sb.WriteString(" ::=")
+	for i, s := range r.RHS {
+		if i == dot {
+			sb.WriteString(" *")
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(s.Name)
+	}
+	if dot == len(r.RHS) {
+		sb.WriteString(" *")
+	}
+	sb.WriteByte('.')
+	return sb.String()
+}
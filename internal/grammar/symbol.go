@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"sort"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// SymbolKind classifies a grammar symbol.
+type SymbolKind int
+
+const (
+	SymbolTerminal SymbolKind = iota
+	SymbolNonterminal
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolTerminal:
+		return "terminal"
+	case SymbolNonterminal:
+		return "nonterminal"
+	}
+	return "unknown"
+}
+
+// Symbol is a terminal or nonterminal in the grammar.
+type Symbol struct {
+	ID         int
+	Name       string
+	Kind       SymbolKind
+	Pos        lex.Position // where the symbol was first seen; zero for built-ins
+	Precedence Precedence
+	Alias      string // display text from a %token "alias" declaration
+}
+
+// IsTerminal reports whether the symbol is a terminal.
+func (s *Symbol) IsTerminal() bool { return s.Kind == SymbolTerminal }
+
+// DisplayName returns the alias if one was declared, otherwise the name.
+func (s *Symbol) DisplayName() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// SymbolTable assigns stable integer IDs to symbols.
+// IDs are allocated sequentially; Finalize renumbers them so that
+// terminals come before nonterminals.
+type SymbolTable struct {
+	symbols []*Symbol // indexed by ID
+	byName  map[string]*Symbol
+}
+
+func newSymbolTable() *SymbolTable {
+	return &SymbolTable{byName: map[string]*Symbol{}}
+}
+
+func (t *SymbolTable) add(name string, kind SymbolKind) *Symbol {
+	if s, ok := t.byName[name]; ok {
+		return s
+	}
+	s := &Symbol{ID: len(t.symbols), Name: name, Kind: kind}
+	t.symbols = append(t.symbols, s)
+	t.byName[name] = s
+	return s
+}
+
+// AddTerminal returns the terminal with the given name, creating it if needed.
+// If the name already exists, the existing symbol is returned unchanged.
+func (t *SymbolTable) AddTerminal(name string) *Symbol {
+	return t.add(name, SymbolTerminal)
+}
+
+// AddNonterminal returns the nonterminal with the given name, creating it if needed.
+// If the name already exists, the existing symbol is returned unchanged.
+func (t *SymbolTable) AddNonterminal(name string) *Symbol {
+	return t.add(name, SymbolNonterminal)
+}
+
+// Lookup returns the symbol with the given name.
+func (t *SymbolTable) Lookup(name string) (*Symbol, bool) {
+	s, ok := t.byName[name]
+	return s, ok
+}
+
+// Symbol returns the symbol with the given ID, or nil if the ID is out of range.
+func (t *SymbolTable) Symbol(id int) *Symbol {
+	if id < 0 || id >= len(t.symbols) {
+		return nil
+	}
+	return t.symbols[id]
+}
+
+// Terminal returns the terminal with the given ID, or nil.
+func (t *SymbolTable) Terminal(id int) *Symbol {
+	if s := t.Symbol(id); s != nil && s.Kind == SymbolTerminal {
+		return s
+	}
+	return nil
+}
+
+// Nonterminal returns the nonterminal with the given ID, or nil.
+func (t *SymbolTable) Nonterminal(id int) *Symbol {
+	if s := t.Symbol(id); s != nil && s.Kind == SymbolNonterminal {
+		return s
+	}
+	return nil
+}
+
+// All returns every symbol in ID order.
+func (t *SymbolTable) All() []*Symbol {
+	return append([]*Symbol(nil), t.symbols...)
+}
+
+// Terminals returns the terminals in ID order, including the EOF marker.
+func (t *SymbolTable) Terminals() []*Symbol {
+	var list []*Symbol
+	for _, s := range t.symbols {
+		if s.Kind == SymbolTerminal {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// Nonterminals returns the nonterminals in ID order.
+func (t *SymbolTable) Nonterminals() []*Symbol {
+	var list []*Symbol
+	for _, s := range t.symbols {
+		if s.Kind == SymbolNonterminal {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// NumSymbols returns the number of symbols, including the EOF marker.
+func (t *SymbolTable) NumSymbols() int {
+	return len(t.symbols)
+}
+
+// NumTerminals returns the number of terminals, including the EOF marker.
+func (t *SymbolTable) NumTerminals() int {
+	n := 0
+	for _, s := range t.symbols {
+		if s.Kind == SymbolTerminal {
+			n++
+		}
+	}
+	return n
+}
+
+// renumber reassigns IDs so that terminals (starting with EOF at 0) come
+// before nonterminals. Relative order within each kind is preserved, so
+// the result is deterministic.
+func (t *SymbolTable) renumber() {
+	sort.SliceStable(t.symbols, func(i, j int) bool {
+		return t.symbols[i].Kind < t.symbols[j].Kind
+	})
+	for i, s := range t.symbols {
+		s.ID = i
+	}
+}
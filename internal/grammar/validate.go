@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import "fmt"
+
+// Validate checks the grammar for common problems and returns diagnostics.
+// It does not modify the grammar. Undefined nonterminals are errors;
+// unreachable and unproductive nonterminals are warnings. A terminal with
+// more than one %fallback is an error.
+func Validate(g *Grammar) []Diagnostic {
+	var diags []Diagnostic
+
+	fallback := map[string]string{}
+	for _, d := range g.DirectivesOf(DirFallback) {
+		for _, name := range d.Symbols[1:] {
+			if to, ok := fallback[name]; ok && to != d.Symbols[0] {
+				diags = append(diags, Diagnostic{Pos: d.Pos, Severity: SeverityError,
+					Message: fmt.Sprintf("terminal %s falls back to both %s and %s", name, to, d.Symbols[0])})
+			}
+			fallback[name] = d.Symbols[0]
+		}
+	}
+
+	defined := map[*Symbol]bool{}
+	for _, r := range g.Rules {
+		defined[r.LHS] = true
+	}
+	for _, nt := range g.Symbols.Nonterminals() {
+		if !defined[nt] {
+			diags = append(diags, Diagnostic{Pos: nt.Pos, Severity: SeverityError,
+				Message: fmt.Sprintf("nonterminal %s is used but has no rules", nt.Name)})
+		}
+	}
+
+	if g.Start != nil {
+		reachable := findReachable(g)
+		for _, nt := range g.Symbols.Nonterminals() {
+			if nt == g.Accept || !defined[nt] || reachable[nt] {
+				continue
+			}
+			diags = append(diags, Diagnostic{Pos: nt.Pos, Severity: SeverityWarning,
+				Message: fmt.Sprintf("nonterminal %s is unreachable from start symbol %s", nt.Name, g.Start.Name)})
+		}
+	}
+
+	productive := findProductive(g)
+	for _, nt := range g.Symbols.Nonterminals() {
+		if nt == g.Accept || !defined[nt] || productive[nt] {
+			continue
+		}
+		diags = append(diags, Diagnostic{Pos: nt.Pos, Severity: SeverityWarning,
+			Message: fmt.Sprintf("nonterminal %s is unproductive (derives no terminal string)", nt.Name)})
+	}
+
+	return diags
+}
+
+// findReachable returns the symbols reachable from the start symbol.
+func findReachable(g *Grammar) map[*Symbol]bool {
+	visited := map[*Symbol]bool{g.Start: true}
+	queue := []*Symbol{g.Start}
+	for len(queue) > 0 {
+		sym := queue[0]
+		queue = queue[1:]
+		for _, r := range g.RulesFor(sym) {
+			for _, s := range r.RHS {
+				if !visited[s] {
+					visited[s] = true
+					queue = append(queue, s)
+				}
+			}
+		}
+	}
+	return visited
+}
+
+// findProductive returns the symbols that derive at least one terminal string.
+func findProductive(g *Grammar) map[*Symbol]bool {
+	productive := map[*Symbol]bool{}
+	for _, t := range g.Symbols.Terminals() {
+		productive[t] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			if productive[r.LHS] {
+				continue
+			}
+			all := true
+			for _, s := range r.RHS {
+				if !productive[s] {
+					all = false
+					break
+				}
+			}
+			if all {
+				productive[r.LHS] = true
+				changed = true
+			}
+		}
+	}
+	return productive
+}
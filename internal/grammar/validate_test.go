@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package grammar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestStartSymbolDefault(t *testing.T) {
+	src := []byte(`
+expr ::= expr PLUS term.
+expr ::= term.
+term ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Start.Name != "expr" {
+		t.Errorf("start = %q, want %q", g.Start.Name, "expr")
+	}
+}
+
+func TestStartSymbolDirective(t *testing.T) {
+	src := []byte(`
+%start_symbol program.
+program ::= expr.
+expr ::= NUM.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	g.Finalize()
+	if g.Start.Name != "program" {
+		t.Errorf("start = %q, want %q", g.Start.Name, "program")
+	}
+}
+
+func TestAugmentedStartRule(t *testing.T) {
+	src := []byte("expr ::= NUM.")
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	g.Finalize()
+
+	found := false
+	for _, r := range g.Rules {
+		if r.LHS.Name == "$accept" {
+			found = true
+			if len(r.RHS) != 1 || r.RHS[0].Name != "expr" {
+				t.Error("augmented rule should be $accept -> expr")
+			}
+			if r != g.AcceptRule {
+				t.Error("AcceptRule should point at the augmented rule")
+			}
+		}
+	}
+	if !found {
+		t.Error("augmented start rule not found")
+	}
+}
+
+func TestUnreachableSymbol(t *testing.T) {
+	src := []byte(`
+expr ::= NUM.
+orphan ::= THING.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	diags, _ := g.Finalize()
+
+	found := false
+	for _, d := range diags {
+		if strings.Contains(d.Message, "orphan") &&
+			strings.Contains(d.Message, "unreachable") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected warning about unreachable 'orphan'")
+	}
+}
+
+func TestUndefinedNonterminal(t *testing.T) {
+	src := []byte("expr ::= expr PLUS missing_nt.")
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	diags, err := g.Finalize()
+
+	if err == nil {
+		t.Error("expected error for undefined nonterminal")
+	}
+	found := false
+	for _, d := range diags {
+		if d.Severity == SeverityError &&
+			strings.Contains(d.Message, "missing_nt") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected diagnostic about missing_nt")
+	}
+}
+
+func TestUnproductiveNonterminal(t *testing.T) {
+	src := []byte(`
+expr ::= NUM.
+expr ::= loop.
+loop ::= loop.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	diags, _ := g.Finalize()
+
+	found := false
+	for _, d := range diags {
+		if strings.Contains(d.Message, "loop") &&
+			strings.Contains(d.Message, "unproductive") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected warning about unproductive 'loop'")
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	src := []byte(`
+%fallback ID IF WHILE.
+%wildcard ANY.
+stmt ::= ID.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	fallbacks := g.Fallbacks()
+	for _, name := range []string{"IF", "WHILE"} {
+		sym, _ := g.Symbols.Lookup(name)
+		if to := fallbacks[sym]; to == nil || to.Name != "ID" {
+			t.Errorf("fallback of %s = %v, want ID", name, to)
+		}
+	}
+	if len(fallbacks) != 2 {
+		t.Errorf("got %d fallbacks, want 2", len(fallbacks))
+	}
+	if w := g.Wildcard(); w == nil || w.Name != "ANY" {
+		t.Errorf("wildcard = %v, want ANY", w)
+	}
+}
+
+func TestTwoFallbacks(t *testing.T) {
+	src := []byte(`
+%fallback ID IF.
+%fallback NAME IF.
+stmt ::= ID.
+`)
+	tokens, _ := lex.Tokenize("test.y", src)
+	g, _, _ := ParseGrammar(tokens)
+	diags, err := g.Finalize()
+
+	if err == nil {
+		t.Error("expected error for a terminal with two fallbacks")
+	}
+	found := false
+	for _, d := range diags {
+		if d.Severity == SeverityError && strings.Contains(d.Message, "IF falls back to both ID and NAME") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected diagnostic about IF, got %v", diags)
+	}
+}
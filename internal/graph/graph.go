@@ -0,0 +1,162 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package graph renders an LR automaton as a Graphviz DOT or Mermaid
+// flowchart. Each state is a node labelled with its kernel items and each
+// transition is an edge.
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// Options selects what the graph shows.
+type Options struct {
+	From      int  // only show states reachable from this state; -1 shows all
+	Conflicts bool // highlight states with unresolved conflicts
+	Labels    bool // label each edge with the symbol it shifts
+}
+
+// view is the part of the automaton that Options selects.
+type view struct {
+	states      []*lalr.State
+	transitions []lalr.Transition
+	conflict    map[int]bool // state ID → has an unresolved conflict
+}
+
+func newView(a *lalr.Automaton, opts Options) (*view, error) {
+	if opts.From >= len(a.States) {
+		return nil, fmt.Errorf("graph: no state %d (the automaton has %d states)", opts.From, len(a.States))
+	}
+	keep := make([]bool, len(a.States))
+	if opts.From < 0 {
+		for i := range keep {
+			keep[i] = true
+		}
+	} else {
+		keep[opts.From] = true
+		queue := []int{opts.From}
+		for len(queue) > 0 {
+			from := queue[0]
+			queue = queue[1:]
+			for _, tr := range a.Transitions {
+				if tr.From == from && !keep[tr.To] {
+					keep[tr.To] = true
+					queue = append(queue, tr.To)
+				}
+			}
+		}
+	}
+
+	v := &view{conflict: map[int]bool{}}
+	for _, s := range a.States {
+		if keep[s.ID] {
+			v.states = append(v.states, s)
+		}
+	}
+	for _, tr := range a.Transitions {
+		if keep[tr.From] {
+			v.transitions = append(v.transitions, tr)
+		}
+	}
+	if opts.Conflicts {
+		for _, c := range lalr.DetectAndResolveConflicts(a, a.Grammar).Conflicts {
+			if !c.Resolved {
+				v.conflict[c.StateID] = true
+			}
+		}
+	}
+	return v, nil
+}
+
+// kernel returns the kernel items of a state, one per line.
+func kernel(a *lalr.Automaton, s *lalr.State) []string {
+	var lines []string
+	for _, item := range s.Kernel(a.Grammar) {
+		lines = append(lines, item.String(a.Grammar))
+	}
+	return lines
+}
+
+// WriteDOT writes the automaton as a Graphviz digraph.
+func WriteDOT(w io.Writer, a *lalr.Automaton, opts Options) error {
+	v, err := newView(a, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph automaton {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  node [shape=box, fontname=\"monospace\"];")
+	for _, s := range v.states {
+		var label strings.Builder
+		fmt.Fprintf(&label, "State %d\\l", s.ID)
+		for _, line := range kernel(a, s) {
+			label.WriteString(dotEscape(line))
+			label.WriteString("\\l")
+		}
+		attrs := ""
+		if v.conflict[s.ID] {
+			attrs = ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(bw, "  s%d [label=\"%s\"%s];\n", s.ID, label.String(), attrs)
+	}
+	for _, tr := range v.transitions {
+		if opts.Labels {
+			fmt.Fprintf(bw, "  s%d -> s%d [label=\"%s\"];\n", tr.From, tr.To, dotEscape(tr.Symbol.Name))
+		} else {
+			fmt.Fprintf(bw, "  s%d -> s%d;\n", tr.From, tr.To)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotEscape escapes a string for a double-quoted DOT label.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// WriteMermaid writes the automaton as a Mermaid flowchart, suitable for a
+// fenced "mermaid" block in Markdown.
+func WriteMermaid(w io.Writer, a *lalr.Automaton, opts Options) error {
+	v, err := newView(a, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for _, s := range v.states {
+		lines := append([]string{fmt.Sprintf("State %d", s.ID)}, kernel(a, s)...)
+		for i, line := range lines {
+			lines[i] = mermaidEscape(line)
+		}
+		fmt.Fprintf(bw, "  s%d[\"%s\"]\n", s.ID, strings.Join(lines, "<br/>"))
+	}
+	for _, tr := range v.transitions {
+		if opts.Labels {
+			fmt.Fprintf(bw, "  s%d -->|\"%s\"| s%d\n", tr.From, mermaidEscape(tr.Symbol.Name), tr.To)
+		} else {
+			fmt.Fprintf(bw, "  s%d --> s%d\n", tr.From, tr.To)
+		}
+	}
+	if len(v.conflict) > 0 {
+		fmt.Fprintln(bw, "  classDef conflict stroke:#c00,stroke-width:3px")
+		for _, s := range v.states {
+			if v.conflict[s.ID] {
+				fmt.Fprintf(bw, "  class s%d conflict\n", s.ID)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// mermaidEscape replaces the characters that end or confuse a quoted
+// Mermaid label with entity codes.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
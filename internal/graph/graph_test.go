@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+const danglingElse = `
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= OTHER.
+expr ::= ID.
+`
+
+func automaton(t *testing.T, src string) *lalr.Automaton {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return lalr.BuildLALR(g)
+}
+
+func TestWriteDOT(t *testing.T) {
+	a := automaton(t, danglingElse)
+	var sb strings.Builder
+	if err := WriteDOT(&sb, a, Options{From: -1, Conflicts: true, Labels: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"digraph automaton {\n",
+		`  s0 [label="State 0\l$accept ::= * stmt.\l"];` + "\n",
+		`  s7 [label="State 7\lstmt ::= IF expr THEN stmt *.\lstmt ::= IF expr THEN stmt * ELSE stmt.\l", color=red, penwidth=2];` + "\n",
+		`  s0 -> s1 [label="IF"];` + "\n",
+		`  s7 -> s8 [label="ELSE"];` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output is missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "color=red"); n != 1 {
+		t.Errorf("%d highlighted states, want 1", n)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	a := automaton(t, danglingElse)
+	var sb strings.Builder
+	if err := WriteMermaid(&sb, a, Options{From: -1}); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"flowchart LR\n",
+		`  s0["State 0<br/>$accept ::= * stmt."]` + "\n",
+		"  s0 --> s1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "classDef") || strings.Contains(out, "-->|") {
+		t.Errorf("unrequested highlight or labels:\n%s", out)
+	}
+	sb.Reset()
+	WriteMermaid(&sb, a, Options{From: -1, Conflicts: true, Labels: true})
+	for _, want := range []string{"  s7 -->|\"ELSE\"| s8\n", "  class s7 conflict\n"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("Mermaid output is missing %q:\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestReachableFrom(t *testing.T) {
+	a := automaton(t, danglingElse)
+	var sb strings.Builder
+	// State 8 follows ELSE; it reaches 1, 2, 4, 5, 6, 7, 8 and 9 but not 0 or 3.
+	if err := WriteDOT(&sb, a, Options{From: 8}); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, id := range []string{"s0 ", "s3 "} {
+		if strings.Contains(out, "  "+id) {
+			t.Errorf("state %s should not be reachable from 8:\n%s", id, out)
+		}
+	}
+	if n := strings.Count(out, "[label="); n != 8 {
+		t.Errorf("%d states, want 8:\n%s", n, out)
+	}
+	if err := WriteDOT(&sb, a, Options{From: 99}); err == nil {
+		t.Error("expected an error for a missing state")
+	}
+}
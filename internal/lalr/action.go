@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "fmt"
+
+// ActionKind is the kind of a parser action.
+type ActionKind int
+
+const (
+	ActionError ActionKind = iota
+	ActionShift
+	ActionReduce
+	ActionAccept
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionError:
+		return "error"
+	case ActionShift:
+		return "shift"
+	case ActionReduce:
+		return "reduce"
+	case ActionAccept:
+		return "accept"
+	}
+	return fmt.Sprintf("ActionKind(%d)", int(k))
+}
+
+// Action is what the parser does in a state on a lookahead terminal.
+type Action struct {
+	Kind      ActionKind
+	State     int // for Shift: target state ID
+	RuleIndex int // for Reduce: rule index
+}
+
+// String returns e.g. "shift 4", "reduce 2", "accept" or "error".
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionShift:
+		return fmt.Sprintf("shift %d", a.State)
+	case ActionReduce:
+		return fmt.Sprintf("reduce %d", a.RuleIndex)
+	}
+	return a.Kind.String()
+}
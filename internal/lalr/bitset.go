@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "math/bits"
+
+// bitset is a fixed-size set of small non-negative integers. The lookahead
+// computation unions many terminal sets, which is much cheaper on words
+// than on maps.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// union adds the members of other to b.
+func (b bitset) union(other bitset) {
+	for i := range other {
+		b[i] |= other[i]
+	}
+}
+
+// addAll adds the members of other to b and reports whether b changed.
+func (b bitset) addAll(other bitset) bool {
+	changed := false
+	for i := range other {
+		if w := b[i] | other[i]; w != b[i] {
+			b[i] = w
+			changed = true
+		}
+	}
+	return changed
+}
+
+// members returns the members of the set in increasing order.
+func (b bitset) members() []int {
+	var list []int
+	for i, w := range b {
+		for w != 0 {
+			n := bits.TrailingZeros64(w)
+			list = append(list, i*64+n)
+			w &^= 1 << uint(n)
+		}
+	}
+	return list
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// Closure expands the item set by adding the initial items of every
+// nonterminal that appears after a dot: if A ::= α * B β is in the set,
+// then B ::= * γ is added for every rule of B. The input is not modified.
+func Closure(items ItemSet, g *grammar.Grammar) ItemSet {
+	return closure(items, g, rulesByLHS(g))
+}
+
+// closure is Closure with the rules already grouped by LHS, which saves
+// rescanning the rule list while building the collection.
+func closure(items ItemSet, g *grammar.Grammar, byLHS map[int][]*grammar.Rule) ItemSet {
+	result := ItemSet{Items: append([]Item(nil), items.Items...)}
+	worklist := append([]Item(nil), items.Items...)
+	for len(worklist) > 0 {
+		item := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		b := item.SymbolAfterDot(g)
+		if b == nil || b.Kind == grammar.SymbolTerminal {
+			continue
+		}
+		for _, r := range byLHS[b.ID] {
+			next := Item{RuleIndex: r.Index, Dot: 0}
+			if result.Add(next) {
+				worklist = append(worklist, next)
+			}
+		}
+	}
+	return result
+}
+
+// rulesByLHS groups the grammar's rules by LHS symbol ID, in rule order.
+func rulesByLHS(g *grammar.Grammar) map[int][]*grammar.Rule {
+	byLHS := map[int][]*grammar.Rule{}
+	for _, r := range g.Rules {
+		byLHS[r.LHS.ID] = append(byLHS[r.LHS.ID], r)
+	}
+	return byLHS
+}
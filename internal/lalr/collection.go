@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"sort"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// Transition is an edge of the automaton: state From goes to state To on
+// Symbol.
+type Transition struct {
+	From   int // state ID
+	Symbol *grammar.Symbol
+	To     int // state ID
+}
+
+// Automaton is the collection of LR states and their transitions.
+type Automaton struct {
+	States      []*State
+	Transitions []Transition // ordered by From, then symbol ID
+	Grammar     *grammar.Grammar
+	Mode        Mode // how the states were constructed
+
+	next []map[int]int // per state: symbol ID → target state
+}
+
+// BuildCanonical builds the canonical collection of LR(0) states for a
+// finalized grammar.
+//
+// States are numbered in breadth-first order from the initial state, and
+// each state's successors are visited in symbol ID order. Both orders
+// depend only on the grammar, so the numbering is the same on every run.
+func BuildCanonical(g *grammar.Grammar) *Automaton {
+	byLHS := rulesByLHS(g)
+	a := &Automaton{Grammar: g}
+	index := map[string]int{} // kernel key → state ID
+
+	add := func(kernel ItemSet, sym *grammar.Symbol) int {
+		k := kernel.key()
+		if id, ok := index[k]; ok {
+			return id
+		}
+		id := len(a.States)
+		index[k] = id
+		a.States = append(a.States, &State{ID: id, Items: closure(kernel, g, byLHS), Symbol: sym})
+		a.next = append(a.next, map[int]int{})
+		return id
+	}
+	add(NewItemSet(Item{RuleIndex: g.AcceptRule.Index, Dot: 0}), nil)
+
+	for id := 0; id < len(a.States); id++ {
+		state := a.States[id]
+		for _, sym := range symbolsAfterDot(state.Items, g) {
+			var kernel ItemSet
+			for _, item := range state.Items.Items {
+				if item.SymbolAfterDot(g) == sym {
+					kernel.Add(Item{RuleIndex: item.RuleIndex, Dot: item.Dot + 1})
+				}
+			}
+			to := add(kernel, sym)
+			a.next[id][sym.ID] = to
+			a.Transitions = append(a.Transitions, Transition{From: id, Symbol: sym, To: to})
+		}
+	}
+	return a
+}
+
+// Next returns the state reached from state from on sym, or -1 if there is
+// no such transition.
+func (a *Automaton) Next(from int, sym *grammar.Symbol) int {
+	if to, ok := a.next[from][sym.ID]; ok {
+		return to
+	}
+	return -1
+}
+
+// symbolsAfterDot returns the distinct symbols that follow a dot in the
+// item set, in symbol ID order.
+func symbolsAfterDot(items ItemSet, g *grammar.Grammar) []*grammar.Symbol {
+	seen := map[int]bool{}
+	var list []*grammar.Symbol
+	for _, item := range items.Items {
+		if sym := item.SymbolAfterDot(g); sym != nil && !seen[sym.ID] {
+			seen[sym.ID] = true
+			list = append(list, sym)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
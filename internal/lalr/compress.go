@@ -0,0 +1,190 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NoBase is the row offset of a state with no entries of that kind.
+const NoBase = -1 << 31
+
+// CompressedTable is a ParseTable packed the way Lemon packs its tables.
+//
+// Each state's most frequent reduce action becomes its default action and
+// is dropped from the row. The remaining ACTION and GOTO rows are then
+// overlaid in one shared array (comb or row-displacement packing): the
+// entry for symbol s of a row with offset base is Entries[base+s], and
+// Check[base+s] == s tells whether the slot belongs to that row. Terminals
+// and nonterminals have distinct symbol IDs, so both kinds of row share the
+// arrays.
+//
+// A lookahead with no entry takes the state's default action, so a state
+// with a default reduction reduces before it reports a syntax error; the
+// error is detected in a later state, before any further shift.
+type CompressedTable struct {
+	NumStates       int
+	NumTerminals    int
+	NumNonterminals int
+
+	Default    []Action // per state: action on a lookahead with no entry
+	ActionBase []int    // per state: offset of the ACTION row, or NoBase
+	GotoBase   []int    // per state: offset of the GOTO row, or NoBase
+	Entries    []Action // packed entries; a GOTO entry is a shift to the target state
+	Check      []int    // per entry: the symbol ID it is for, or -1 if unused
+}
+
+// entry is one cell of a row to pack.
+type entry struct {
+	sym int
+	act Action
+}
+
+// Compress packs a parse table.
+//
+// Rows are placed from the fullest to the emptiest, each at the lowest
+// offset where it fits; identical rows share an offset. Ties are broken
+// by state ID, so the result depends only on the table.
+func Compress(t *ParseTable) *CompressedTable {
+	c := &CompressedTable{
+		NumStates:       t.NumStates,
+		NumTerminals:    t.NumTerminals,
+		NumNonterminals: t.NumNonterminals,
+		Default:         make([]Action, t.NumStates),
+		ActionBase:      make([]int, t.NumStates),
+		GotoBase:        make([]int, t.NumStates),
+	}
+
+	type row struct {
+		entries []entry
+		base    *int
+	}
+	var rows []row
+	for s := 0; s < t.NumStates; s++ {
+		c.Default[s] = defaultReduction(t.Action[s])
+		var actions []entry
+		for term, act := range t.Action[s] {
+			if act.Kind != ActionError && act != c.Default[s] {
+				actions = append(actions, entry{sym: term, act: act})
+			}
+		}
+		if c.Default[s].Kind == ActionReduce {
+			// %nonassoc errors must not fall through to the default.
+			for _, term := range t.Nonassoc[s] {
+				actions = append(actions, entry{sym: term, act: Action{Kind: ActionError}})
+			}
+			sort.Slice(actions, func(i, j int) bool { return actions[i].sym < actions[j].sym })
+		}
+		var gotos []entry
+		for nt, to := range t.Goto[s] {
+			if to >= 0 {
+				gotos = append(gotos, entry{sym: t.NumTerminals + nt, act: Action{Kind: ActionShift, State: to}})
+			}
+		}
+		rows = append(rows, row{actions, &c.ActionBase[s]}, row{gotos, &c.GotoBase[s]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return len(rows[i].entries) > len(rows[j].entries) })
+
+	used := map[int]bool{}     // offsets taken by a row
+	shared := map[string]int{} // row key → offset
+	for _, r := range rows {
+		if len(r.entries) == 0 {
+			*r.base = NoBase
+			continue
+		}
+		key := rowKey(r.entries)
+		if base, ok := shared[key]; ok {
+			*r.base = base
+			continue
+		}
+		base := -r.entries[0].sym
+		for used[base] || !c.fits(r.entries, base) {
+			base++
+		}
+		c.place(r.entries, base)
+		used[base] = true
+		shared[key] = base
+		*r.base = base
+	}
+	return c
+}
+
+// defaultReduction returns the reduce action that fills the most cells of
+// a row, preferring the lower rule on a tie, or an error action if the row
+// has no reduction.
+func defaultReduction(actions []Action) Action {
+	count := map[int]int{}
+	for _, act := range actions {
+		if act.Kind == ActionReduce {
+			count[act.RuleIndex]++
+		}
+	}
+	best := Action{Kind: ActionError}
+	for rule, n := range count {
+		if b := count[best.RuleIndex]; best.Kind == ActionError || n > b || n == b && rule < best.RuleIndex {
+			best = Action{Kind: ActionReduce, RuleIndex: rule}
+		}
+	}
+	return best
+}
+
+func rowKey(entries []entry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "%d:%d:%d:%d;", e.sym, e.act.Kind, e.act.State, e.act.RuleIndex)
+	}
+	return sb.String()
+}
+
+// fits reports whether every entry of a row lands on a free slot at base.
+func (c *CompressedTable) fits(entries []entry, base int) bool {
+	for _, e := range entries {
+		if i := base + e.sym; i < len(c.Check) && c.Check[i] >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// place writes a row's entries at base, growing the arrays as needed.
+func (c *CompressedTable) place(entries []entry, base int) {
+	for _, e := range entries {
+		i := base + e.sym
+		for len(c.Check) <= i {
+			c.Check = append(c.Check, -1)
+			c.Entries = append(c.Entries, Action{})
+		}
+		c.Check[i] = e.sym
+		c.Entries[i] = e.act
+	}
+}
+
+// Action returns the action for a state and lookahead terminal.
+func (c *CompressedTable) Action(state, terminal int) Action {
+	if base := c.ActionBase[state]; base != NoBase {
+		if i := base + terminal; i >= 0 && i < len(c.Check) && c.Check[i] == terminal {
+			return c.Entries[i]
+		}
+	}
+	return c.Default[state]
+}
+
+// Goto returns the state entered from state after reducing to the
+// nonterminal with symbol ID nt, or -1 if there is none.
+func (c *CompressedTable) Goto(state, nt int) int {
+	if base := c.GotoBase[state]; base != NoBase {
+		if i := base + nt; i >= 0 && i < len(c.Check) && c.Check[i] == nt {
+			return c.Entries[i].State
+		}
+	}
+	return -1
+}
+
+// Size returns the number of table cells the compressed form needs: the
+// packed entries and their checks, and a default and two offsets per
+// state.
+func (c *CompressedTable) Size() int {
+	return 2*len(c.Entries) + 3*c.NumStates
+}
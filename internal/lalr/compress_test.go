@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// checkCompressed verifies that a compressed table answers like the full
+// one: the same action wherever the full table has one, the same goto
+// everywhere, and only an error or the default reduction elsewhere.
+func checkCompressed(t *testing.T, full *ParseTable, c *CompressedTable) {
+	t.Helper()
+	for s := 0; s < full.NumStates; s++ {
+		for term := 0; term < full.NumTerminals; term++ {
+			want, got := full.Action[s][term], c.Action(s, term)
+			switch {
+			case want.Kind != ActionError:
+				if got != want {
+					t.Errorf("state %d, terminal %d: got %v, want %v", s, term, got, want)
+				}
+			case slices.Contains(full.Nonassoc[s], term):
+				if got.Kind != ActionError {
+					t.Errorf("state %d, terminal %d: got %v, want a %%nonassoc error", s, term, got)
+				}
+			case got.Kind != ActionError && got != c.Default[s]:
+				t.Errorf("state %d, terminal %d: got %v, want error or the default", s, term, got)
+			}
+		}
+		for nt := 0; nt < full.NumNonterminals; nt++ {
+			if got, want := c.Goto(s, full.NumTerminals+nt), full.Goto[s][nt]; got != want {
+				t.Errorf("GOTO[%d, %d] = %d, want %d", s, nt, got, want)
+			}
+		}
+	}
+}
+
+func TestCompressArith(t *testing.T) {
+	g := buildAugmentedArith(t)
+	full := BuildTables(BuildLALR(g), g)
+	c := Compress(full)
+	checkCompressed(t, full, c)
+	term := findRule(t, g, "term", "NUM")
+	if c.Default[1] != (Action{Kind: ActionReduce, RuleIndex: term.Index}) {
+		t.Errorf("default of state 1 = %v, want reduce %d", c.Default[1], term.Index)
+	}
+	if c.ActionBase[1] != NoBase {
+		t.Errorf("state 1 has only its default reduction but an ACTION row at %d", c.ActionBase[1])
+	}
+	if c.Default[0].Kind != ActionError {
+		t.Errorf("default of state 0 = %v, want error", c.Default[0])
+	}
+}
+
+func TestCompressGrammars(t *testing.T) {
+	for name, src := range map[string]string{
+		"precedence": `
+%left PLUS MINUS.
+%left TIMES.
+%nonassoc EQ.
+%right POW.
+stmt ::= expr SEMI.
+stmt ::= ID ASSIGN expr SEMI.
+expr ::= expr PLUS expr.
+expr ::= expr MINUS expr.
+expr ::= expr TIMES expr.
+expr ::= expr EQ expr.
+expr ::= expr POW expr.
+expr ::= LPAREN expr RPAREN.
+expr ::= ID.
+expr ::= NUM.
+`,
+		"dangling else": `
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= OTHER.
+expr ::= ID.
+`,
+		"lists": `
+prog ::= decls stmts.
+decls ::= decls decl.
+decls ::= .
+decl ::= VAR ids SEMI.
+ids ::= ids COMMA ID.
+ids ::= ID.
+stmts ::= stmt stmts.
+stmts ::= .
+stmt ::= ID ASSIGN ID SEMI.
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := mustGrammar(t, src)
+			full := BuildTables(BuildLALR(g), g)
+			c := Compress(full)
+			checkCompressed(t, full, c)
+			if c.Size() >= full.Size() {
+				t.Errorf("compressed size %d is not below %d", c.Size(), full.Size())
+			}
+			if again := Compress(full); !reflect.DeepEqual(again, c) {
+				t.Error("Compress is not deterministic")
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"fmt"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// ConflictKind distinguishes shift/reduce from reduce/reduce conflicts.
+type ConflictKind int
+
+const (
+	ShiftReduce ConflictKind = iota
+	ReduceReduce
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ShiftReduce:
+		return "shift/reduce"
+	case ReduceReduce:
+		return "reduce/reduce"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// Conflict is a pair of actions that compete in a state on the same
+// lookahead terminal.
+type Conflict struct {
+	Kind        ConflictKind
+	StateID     int
+	Terminal    *grammar.Symbol // the conflicting lookahead terminal
+	ShiftItem   *LAItem         // for shift/reduce: the shift item
+	ReduceItem  *LAItem         // the reduce item
+	ReduceItem2 *LAItem         // for reduce/reduce: the second reduce item
+	Resolution  string          // "shift", "reduce" or "error"
+	Resolved    bool            // true if precedence/associativity resolved it
+}
+
+// String describes the conflict, e.g.
+// "state 5: shift/reduce conflict on PLUS between shift and expr ::= expr PLUS expr. (resolved: reduce)".
+func (c Conflict) String(g *grammar.Grammar) string {
+	var between string
+	if c.Kind == ShiftReduce {
+		between = "shift and " + g.Rules[c.ReduceItem.RuleIndex].String()
+	} else {
+		between = g.Rules[c.ReduceItem.RuleIndex].String() + " and " + g.Rules[c.ReduceItem2.RuleIndex].String()
+	}
+	how := "resolved"
+	if !c.Resolved {
+		how = "unresolved, chose"
+	}
+	return fmt.Sprintf("state %d: %s conflict on %s between %s (%s: %s)",
+		c.StateID, c.Kind, c.Terminal.Name, between, how, c.Resolution)
+}
+
+// ConflictReport lists every conflict in an automaton.
+type ConflictReport struct {
+	Conflicts  []Conflict
+	Resolved   int
+	Unresolved int
+}
+
+// ShiftReduce returns the number of unresolved shift/reduce conflicts.
+func (r *ConflictReport) ShiftReduce() int {
+	return r.count(ShiftReduce)
+}
+
+// ReduceReduce returns the number of unresolved reduce/reduce conflicts.
+func (r *ConflictReport) ReduceReduce() int {
+	return r.count(ReduceReduce)
+}
+
+func (r *ConflictReport) count(kind ConflictKind) int {
+	n := 0
+	for _, c := range r.Conflicts {
+		if c.Kind == kind && !c.Resolved {
+			n++
+		}
+	}
+	return n
+}
+
+// DetectAndResolveConflicts finds the conflicts in every state of an
+// automaton with lookaheads and records how each one was settled.
+//
+// A shift/reduce conflict is resolved Lemon-style when both the terminal
+// and the rule have a precedence: the higher precedence wins, and equal
+// precedence falls back to associativity (left reduces, right shifts,
+// nonassoc makes the input an error). Otherwise the shift is kept and the
+// conflict is unresolved. A reduce/reduce conflict is always unresolved;
+// the rule that appears first in the grammar wins.
+//
+// Conflicts are listed by state, then terminal ID, then rule index.
+func DetectAndResolveConflicts(a *Automaton, g *grammar.Grammar) *ConflictReport {
+	report := &ConflictReport{}
+	for _, s := range a.States {
+		_, conflicts := stateActions(a, s, g)
+		for _, c := range conflicts {
+			if c.Resolved {
+				report.Resolved++
+			} else {
+				report.Unresolved++
+			}
+		}
+		report.Conflicts = append(report.Conflicts, conflicts...)
+	}
+	return report
+}
+
+// stateActions returns the action chosen for each terminal in a state,
+// indexed by terminal ID, together with the conflicts met on the way.
+func stateActions(a *Automaton, s *State, g *grammar.Grammar) ([]Action, []Conflict) {
+	nterm := g.NumTerminals()
+	shifts := make([]*LAItem, nterm)
+	reduces := make([][]*LAItem, nterm)
+	for i := range s.LAItems {
+		item := &s.LAItems[i]
+		if sym := item.SymbolAfterDot(g); sym != nil {
+			if sym.Kind == grammar.SymbolTerminal && shifts[sym.ID] == nil {
+				shifts[sym.ID] = item
+			}
+			continue
+		}
+		for t := 0; t < nterm; t++ {
+			if item.Lookahead[t] {
+				reduces[t] = append(reduces[t], item)
+			}
+		}
+	}
+
+	actions := make([]Action, nterm)
+	var conflicts []Conflict
+	for t := 0; t < nterm; t++ {
+		if shifts[t] == nil && reduces[t] == nil {
+			continue
+		}
+		var cs []Conflict
+		actions[t], cs = decide(g, s.ID, g.Symbols.Symbol(t), shifts[t], a.next[s.ID][t], reduces[t])
+		conflicts = append(conflicts, cs...)
+	}
+	return actions, conflicts
+}
+
+// decide picks the action for one state and terminal from the shift item
+// (or nil) and the reduce items, which must be in rule order.
+func decide(g *grammar.Grammar, stateID int, t *grammar.Symbol, shift *LAItem, shiftTo int, reduces []*LAItem) (Action, []Conflict) {
+	var act Action
+	var winner *LAItem    // the reduce item behind act, if any
+	var overruled *LAItem // the reduce item that a %nonassoc error overruled, if any
+	if shift != nil {
+		act = Action{Kind: ActionShift, State: shiftTo}
+	}
+	var conflicts []Conflict
+	for _, item := range reduces {
+		reduce := Action{Kind: ActionReduce, RuleIndex: item.RuleIndex}
+		if g.Rules[item.RuleIndex] == g.AcceptRule {
+			reduce = Action{Kind: ActionAccept}
+		}
+		switch {
+		case shift == nil && winner == nil:
+			act, winner = reduce, item
+		case winner == nil && act.Kind == ActionShift:
+			c := resolveShiftReduce(g, t, g.Rules[item.RuleIndex])
+			c.StateID, c.ShiftItem, c.ReduceItem = stateID, shift, item
+			conflicts = append(conflicts, c)
+			switch c.Resolution {
+			case "reduce":
+				act, winner = reduce, item
+			case "error":
+				act, overruled = Action{Kind: ActionError}, item
+			}
+		case winner != nil:
+			// The earlier rule has already won.
+			conflicts = append(conflicts, Conflict{
+				Kind: ReduceReduce, StateID: stateID, Terminal: t,
+				ReduceItem: winner, ReduceItem2: item, Resolution: "reduce",
+			})
+		case overruled != nil:
+			// The earlier rule would have won, but the cell is an error.
+			conflicts = append(conflicts, Conflict{
+				Kind: ReduceReduce, StateID: stateID, Terminal: t,
+				ReduceItem: overruled, ReduceItem2: item, Resolution: "error",
+			})
+		}
+	}
+	return act, conflicts
+}
+
+// resolveShiftReduce settles a shift/reduce conflict on t against rule r
+// using precedence and associativity.
+func resolveShiftReduce(g *grammar.Grammar, t *grammar.Symbol, r *grammar.Rule) Conflict {
+	c := Conflict{Kind: ShiftReduce, Terminal: t, Resolution: "shift"}
+	tp, rp := t.Precedence, r.Precedence
+	if tp.IsZero() || rp.IsZero() {
+		return c
+	}
+	c.Resolved = true
+	switch {
+	case rp.Level > tp.Level:
+		c.Resolution = "reduce"
+	case tp.Level > rp.Level:
+		c.Resolution = "shift"
+	case tp.Assoc == grammar.AssocLeft:
+		c.Resolution = "reduce"
+	case tp.Assoc == grammar.AssocRight:
+		c.Resolution = "shift"
+	default:
+		c.Resolution = "error"
+	}
+	return c
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"testing"
+)
+
+func TestNoConflicts(t *testing.T) {
+	g := buildAugmentedArith(t)
+	report := DetectAndResolveConflicts(BuildLALR(g), g)
+	if len(report.Conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %d", len(report.Conflicts))
+	}
+}
+
+func TestShiftReduceWithPrecedence(t *testing.T) {
+	g := mustGrammar(t, `
+%left PLUS.
+%left TIMES.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= NUM.
+`)
+	report := DetectAndResolveConflicts(BuildLALR(g), g)
+	if len(report.Conflicts) == 0 {
+		t.Fatal("expected conflicts to be detected (and resolved)")
+	}
+	if report.Unresolved != 0 || report.Resolved != len(report.Conflicts) {
+		t.Errorf("resolved %d, unresolved %d, want all %d resolved", report.Resolved, report.Unresolved, len(report.Conflicts))
+	}
+	// expr PLUS expr * TIMES shifts; expr TIMES expr * PLUS reduces.
+	for _, c := range report.Conflicts {
+		rule := g.Rules[c.ReduceItem.RuleIndex]
+		want := "reduce"
+		if rule.RHS[1].Name == "PLUS" && c.Terminal.Name == "TIMES" {
+			want = "shift"
+		}
+		if c.Resolution != want {
+			t.Errorf("%s: resolution %q, want %q", c.String(g), c.Resolution, want)
+		}
+	}
+}
+
+func TestReduceReduceConflict(t *testing.T) {
+	g := mustGrammar(t, `
+s ::= a.
+s ::= b.
+a ::= X.
+b ::= X.
+`)
+	report := DetectAndResolveConflicts(BuildLALR(g), g)
+	if report.ReduceReduce() != 1 || report.ShiftReduce() != 0 {
+		t.Fatalf("got %d r/r and %d s/r conflicts, want 1 and 0", report.ReduceReduce(), report.ShiftReduce())
+	}
+	c := report.Conflicts[0]
+	if c.Resolved || g.Rules[c.ReduceItem.RuleIndex].LHS.Name != "a" {
+		t.Errorf("%s: want unresolved with a ::= X. winning", c.String(g))
+	}
+}
+
+func TestAssociativity(t *testing.T) {
+	for _, tc := range []struct {
+		assoc, want string
+	}{
+		{"%left", "reduce"},
+		{"%right", "shift"},
+		{"%nonassoc", "error"},
+	} {
+		g := mustGrammar(t, tc.assoc+` OP.
+expr ::= expr OP expr.
+expr ::= NUM.
+`)
+		report := DetectAndResolveConflicts(BuildLALR(g), g)
+		if len(report.Conflicts) != 1 {
+			t.Fatalf("%s: %d conflicts, want 1", tc.assoc, len(report.Conflicts))
+		}
+		if c := report.Conflicts[0]; c.Kind != ShiftReduce || !c.Resolved || c.Resolution != tc.want {
+			t.Errorf("%s: %s, want resolution %q", tc.assoc, c.String(g), tc.want)
+		}
+	}
+}
+
+// TestNonassocThenReduceReduce checks that a reduce item after one that
+// a %nonassoc error overruled is still reported as a reduce/reduce conflict.
+func TestNonassocThenReduceReduce(t *testing.T) {
+	g := mustGrammar(t, `
+%nonassoc EQ.
+s ::= e.
+s ::= f EQ X.
+e ::= e EQ e.
+e ::= X.
+f ::= e EQ e.
+`)
+	report := DetectAndResolveConflicts(BuildLALR(g), g)
+	var rr []Conflict
+	for _, c := range report.Conflicts {
+		if c.Kind == ReduceReduce && c.Terminal.Name == "EQ" {
+			rr = append(rr, c)
+		}
+	}
+	if len(rr) != 1 {
+		t.Fatalf("got %d r/r conflicts on EQ, want 1: %v", len(rr), report.Conflicts)
+	}
+	c := rr[0]
+	if c.Resolved || c.Resolution != "error" || g.Rules[c.ReduceItem.RuleIndex].LHS.Name != "e" || g.Rules[c.ReduceItem2.RuleIndex].LHS.Name != "f" {
+		t.Errorf("%s: want unresolved between e ::= e EQ e. and f ::= e EQ e., leaving the error", c.String(g))
+	}
+	if report.Unresolved != report.ReduceReduce() {
+		t.Errorf("unresolved %d, want the %d r/r conflicts", report.Unresolved, report.ReduceReduce())
+	}
+}
+
+func TestShiftReduceWithoutPrecedence(t *testing.T) {
+	g := mustGrammar(t, `
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= X.
+expr ::= Y.
+`)
+	report := DetectAndResolveConflicts(BuildLALR(g), g)
+	if report.ShiftReduce() != 1 || report.Unresolved != 1 {
+		t.Fatalf("got %d unresolved s/r, want 1", report.ShiftReduce())
+	}
+	want := "state 7: shift/reduce conflict on ELSE between shift and stmt ::= IF expr THEN stmt. (unresolved, chose: shift)"
+	if got := report.Conflicts[0].String(g); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
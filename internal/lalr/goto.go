@@ -0,0 +1,25 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// Goto returns the closure of the items in the set that have sym after the
+// dot, with the dot advanced past sym. The result is empty if no item
+// expects sym.
+func Goto(items ItemSet, sym *grammar.Symbol, g *grammar.Grammar) ItemSet {
+	return gotoSet(items, sym, g, rulesByLHS(g))
+}
+
+func gotoSet(items ItemSet, sym *grammar.Symbol, g *grammar.Grammar, byLHS map[int][]*grammar.Rule) ItemSet {
+	var advanced ItemSet
+	for _, item := range items.Items {
+		if item.SymbolAfterDot(g) == sym {
+			advanced.Add(Item{RuleIndex: item.RuleIndex, Dot: item.Dot + 1})
+		}
+	}
+	if advanced.Len() == 0 {
+		return advanced
+	}
+	return closure(advanced, g, byLHS)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// BuildIELR builds an automaton with the goal of IELR(1): the language
+// and conflicts of canonical LR(1) with close to LALR(1) state counts.
+//
+// Rather than splitting LALR states as Denny and Malloy's algorithm does,
+// it starts from the canonical LR(1) states and merges those with the same
+// core wherever the merge is harmless. A merge is harmless when, for every
+// terminal, each member state that acts on it would choose the same action,
+// with the same number of unresolved conflicts, as the merged state. States
+// are first grouped greedily by that test, then groups are split until
+// every member of a group moves to the same group on each symbol, and the
+// two steps repeat until neither changes anything. A grammar without
+// merge-induced conflicts gets exactly the LALR(1) automaton. Building the
+// canonical states first means that BuildIELR takes as much time and memory
+// as BuildLR1; only the result is small.
+func BuildIELR(g *grammar.Grammar) *Automaton {
+	states := buildLR1States(g)
+	class := make([]int, len(states))
+
+	// Start with one group per core, in order of first appearance.
+	cores := map[string]int{}
+	for id, s := range states {
+		k := s.items.key()
+		if _, ok := cores[k]; !ok {
+			cores[k] = len(cores)
+		}
+		class[id] = cores[k]
+	}
+	for {
+		n := countClasses(class)
+		class = splitIncompatible(g, states, class)
+		class = splitBySuccessors(g, states, class)
+		if countClasses(class) == n {
+			break
+		}
+	}
+
+	// Number the merged states breadth-first from the start state.
+	a := &Automaton{Grammar: g, Mode: ModeIELR}
+	members := map[int][]int{}
+	for id := range states {
+		members[class[id]] = append(members[class[id]], id)
+	}
+	number := map[int]int{class[0]: 0}
+	order := []int{class[0]}
+	for i := 0; i < len(order); i++ {
+		c := order[i]
+		rep := states[members[c][0]]
+		la := make([]bitset, rep.items.Len())
+		for j := range la {
+			la[j] = newBitset(g.NumTerminals())
+			for _, m := range members[c] {
+				la[j].union(states[m].la[j])
+			}
+		}
+		a.States = append(a.States, &State{ID: i, Items: rep.items, Symbol: rep.sym, LAItems: reduceLookaheads(g, rep.items, la)})
+		next := map[int]int{}
+		for _, sym := range symbolsAfterDot(rep.items, g) {
+			to := class[rep.next[sym.ID]]
+			if _, ok := number[to]; !ok {
+				number[to] = len(order)
+				order = append(order, to)
+			}
+			next[sym.ID] = number[to]
+		}
+		a.next = append(a.next, next)
+	}
+	a.Transitions = transitions(a)
+	return a
+}
+
+func countClasses(class []int) int {
+	seen := map[int]bool{}
+	for _, c := range class {
+		seen[c] = true
+	}
+	return len(seen)
+}
+
+// splitIncompatible partitions each class greedily into groups whose
+// members can be merged without changing any member's actions. States are
+// placed in ID order into the first group that accepts them.
+func splitIncompatible(g *grammar.Grammar, states []*lr1State, class []int) []int {
+	groups := map[int][][]int{} // old class → new groups
+	var order []int
+	for id := range states {
+		c := class[id]
+		if _, ok := groups[c]; !ok {
+			order = append(order, c)
+		}
+		placed := false
+		for i, grp := range groups[c] {
+			if compatible(g, states, append(grp[:len(grp):len(grp)], id)) {
+				groups[c][i] = append(grp, id)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups[c] = append(groups[c], []int{id})
+		}
+	}
+	result := make([]int, len(states))
+	next := 0
+	for _, c := range order {
+		for _, grp := range groups[c] {
+			for _, id := range grp {
+				result[id] = next
+			}
+			next++
+		}
+	}
+	return result
+}
+
+// splitBySuccessors splits each class so that its members agree on the
+// class of their successor for every symbol.
+func splitBySuccessors(g *grammar.Grammar, states []*lr1State, class []int) []int {
+	index := map[string]int{}
+	result := make([]int, len(states))
+	for id, s := range states {
+		var sb strings.Builder
+		sb.WriteString(strconv.Itoa(class[id]))
+		for _, sym := range symbolsAfterDot(s.items, g) {
+			sb.WriteByte(' ')
+			sb.WriteString(strconv.Itoa(class[s.next[sym.ID]]))
+		}
+		k := sb.String()
+		if _, ok := index[k]; !ok {
+			index[k] = len(index)
+		}
+		result[id] = index[k]
+	}
+	return result
+}
+
+// compatible reports whether the states, which share a core, can be
+// merged: on every terminal, each state that acts on it must choose the
+// same action, with the same number of unresolved conflicts, alone as
+// when merged.
+func compatible(g *grammar.Grammar, states []*lr1State, ids []int) bool {
+	items := states[ids[0]].items
+	nterm := g.NumTerminals()
+	merged := make([]bitset, items.Len())
+	for j, item := range items.Items {
+		merged[j] = newBitset(nterm)
+		if item.IsReduce(g) {
+			for _, id := range ids {
+				merged[j].union(states[id].la[j])
+			}
+		}
+	}
+	for t := 0; t < nterm; t++ {
+		act, unresolved := cellAction(g, items, merged, t)
+		for _, id := range ids {
+			s := states[id]
+			if !actsOn(g, items, s.la, t) {
+				continue
+			}
+			if a, u := cellAction(g, items, s.la, t); a != act || u != unresolved {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// actsOn reports whether a state with the given items and lookaheads has
+// any action on terminal t.
+func actsOn(g *grammar.Grammar, items ItemSet, la []bitset, t int) bool {
+	for j, item := range items.Items {
+		if sym := item.SymbolAfterDot(g); sym != nil && sym.ID == t {
+			return true
+		} else if sym == nil && la[j].has(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// cellAction returns the action a state would choose on terminal t and the
+// number of unresolved conflicts involved. Shift targets are not compared.
+func cellAction(g *grammar.Grammar, items ItemSet, la []bitset, t int) (Action, int) {
+	var shift *LAItem
+	var reduces []*LAItem
+	for j, item := range items.Items {
+		if sym := item.SymbolAfterDot(g); sym != nil {
+			if sym.ID == t && shift == nil {
+				shift = &LAItem{Item: item}
+			}
+		} else if la[j].has(t) {
+			reduces = append(reduces, &LAItem{Item: item})
+		}
+	}
+	act, conflicts := decide(g, -1, g.Symbols.Symbol(t), shift, 0, reduces)
+	unresolved := 0
+	for _, c := range conflicts {
+		if !c.Resolved {
+			unresolved++
+		}
+	}
+	return act, unresolved
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package lalr builds the LR automaton for a grammar: LR(0) items, closure,
+// GOTO, the canonical collection of states and LALR(1) lookaheads.
+package lalr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// Item is an LR(0) item: a rule with a dot position.
+// The dot ranges from 0 (before the first RHS symbol) to len(RHS).
+type Item struct {
+	RuleIndex int // index into Grammar.Rules
+	Dot       int // position of the dot (0 = before first symbol)
+}
+
+// SymbolAfterDot returns the symbol immediately after the dot, or nil if
+// the dot is at the end (reduce item).
+func (item Item) SymbolAfterDot(g *grammar.Grammar) *grammar.Symbol {
+	rhs := g.Rules[item.RuleIndex].RHS
+	if item.Dot < len(rhs) {
+		return rhs[item.Dot]
+	}
+	return nil
+}
+
+// IsReduce reports whether the dot is at the end of the RHS.
+func (item Item) IsReduce(g *grammar.Grammar) bool {
+	return item.Dot >= len(g.Rules[item.RuleIndex].RHS)
+}
+
+// IsKernel reports whether the item is a kernel item: the initial
+// $accept item or any item with the dot past the first position.
+func (item Item) IsKernel(g *grammar.Grammar) bool {
+	return item.Dot > 0 || g.Rules[item.RuleIndex] == g.AcceptRule
+}
+
+// String returns the item's rule with a "*" at the dot.
+func (item Item) String(g *grammar.Grammar) string {
+	return g.Rules[item.RuleIndex].StringWithDot(item.Dot)
+}
+
+// less orders items by rule index, then dot.
+func (item Item) less(other Item) bool {
+	if item.RuleIndex != other.RuleIndex {
+		return item.RuleIndex < other.RuleIndex
+	}
+	return item.Dot < other.Dot
+}
+
+// ItemSet is an ordered, deduplicated set of items.
+// Items are kept sorted by rule index, then dot, so that equal sets have
+// equal slices.
+type ItemSet struct {
+	Items []Item
+}
+
+// NewItemSet returns a set containing the given items.
+func NewItemSet(items ...Item) ItemSet {
+	var s ItemSet
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// Add inserts item in order. It returns true if the item was new.
+func (s *ItemSet) Add(item Item) bool {
+	i := sort.Search(len(s.Items), func(i int) bool { return !s.Items[i].less(item) })
+	if i < len(s.Items) && s.Items[i] == item {
+		return false
+	}
+	s.Items = append(s.Items, Item{})
+	copy(s.Items[i+1:], s.Items[i:])
+	s.Items[i] = item
+	return true
+}
+
+// Contains reports whether item is in the set.
+func (s ItemSet) Contains(item Item) bool {
+	return s.index(item) >= 0
+}
+
+// index returns the position of item in the set, or -1.
+func (s ItemSet) index(item Item) int {
+	i := sort.Search(len(s.Items), func(i int) bool { return !s.Items[i].less(item) })
+	if i < len(s.Items) && s.Items[i] == item {
+		return i
+	}
+	return -1
+}
+
+// Len returns the number of items in the set.
+func (s ItemSet) Len() int {
+	return len(s.Items)
+}
+
+// Equal reports whether both sets hold the same items.
+func (s ItemSet) Equal(other ItemSet) bool {
+	if len(s.Items) != len(other.Items) {
+		return false
+	}
+	for i := range s.Items {
+		if s.Items[i] != other.Items[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns a string that identifies the set, for use as a map key.
+func (s ItemSet) key() string {
+	var sb strings.Builder
+	for _, item := range s.Items {
+		sb.WriteString(strconv.Itoa(item.RuleIndex))
+		sb.WriteByte('.')
+		sb.WriteString(strconv.Itoa(item.Dot))
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+// buildAugmentedArith builds
+//
+//	expr ::= expr PLUS term.
+//	expr ::= term.
+//	term ::= NUM.
+//
+// Finalize appends $accept ::= expr as rule 3.
+func buildAugmentedArith(t *testing.T) *grammar.Grammar {
+	t.Helper()
+	g := grammar.NewGrammar()
+	g.AddTerminal("PLUS")
+	g.AddTerminal("NUM")
+	g.AddNonterminal("expr")
+	g.AddNonterminal("term")
+	for _, r := range [][]string{{"expr", "expr", "PLUS", "term"}, {"expr", "term"}, {"term", "NUM"}} {
+		if _, err := g.AddRule(r[0], r[1:], ""); err != nil {
+			t.Fatalf("AddRule: %v", err)
+		}
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func findRule(t *testing.T, g *grammar.Grammar, lhs string, rhs ...string) *grammar.Rule {
+	t.Helper()
+	for _, r := range g.Rules {
+		if r.LHS.Name != lhs || len(r.RHS) != len(rhs) {
+			continue
+		}
+		match := true
+		for i, s := range r.RHS {
+			match = match && s.Name == rhs[i]
+		}
+		if match {
+			return r
+		}
+	}
+	t.Fatalf("rule %s ::= %s not found", lhs, strings.Join(rhs, " "))
+	return nil
+}
+
+func lookup(t *testing.T, g *grammar.Grammar, name string) *grammar.Symbol {
+	t.Helper()
+	sym, ok := g.Symbols.Lookup(name)
+	if !ok {
+		t.Fatalf("symbol %q not found", name)
+	}
+	return sym
+}
+
+// lookaheads returns the lookahead names of every reduce item of rule r,
+// one entry per state, as "state: names".
+func lookaheads(a *Automaton, r *grammar.Rule) []string {
+	g := a.Grammar
+	var list []string
+	for _, s := range a.States {
+		for _, item := range s.Reductions(g) {
+			if item.RuleIndex != r.Index {
+				continue
+			}
+			var names []string
+			for _, sym := range g.Symbols.Terminals() {
+				if item.Lookahead[sym.ID] {
+					names = append(names, sym.Name)
+				}
+			}
+			list = append(list, fmt.Sprintf("%d: %s", s.ID, strings.Join(names, " ")))
+		}
+	}
+	return list
+}
+
+func TestClosureSingleTerminal(t *testing.T) {
+	g := buildAugmentedArith(t)
+	r := findRule(t, g, "term", "NUM")
+	closed := Closure(NewItemSet(Item{RuleIndex: r.Index, Dot: 0}), g)
+	if closed.Len() != 1 {
+		t.Errorf("closure size = %d, want 1", closed.Len())
+	}
+}
+
+func TestClosureExpandsNonterminal(t *testing.T) {
+	g := buildAugmentedArith(t)
+	closed := Closure(NewItemSet(Item{RuleIndex: g.AcceptRule.Index, Dot: 0}), g)
+	if closed.Len() != 4 {
+		t.Errorf("closure size = %d, want 4", closed.Len())
+	}
+	for _, r := range []*grammar.Rule{
+		findRule(t, g, "expr", "expr", "PLUS", "term"),
+		findRule(t, g, "expr", "term"),
+		findRule(t, g, "term", "NUM"),
+	} {
+		if !closed.Contains(Item{RuleIndex: r.Index, Dot: 0}) {
+			t.Errorf("missing: %s", r.StringWithDot(0))
+		}
+	}
+}
+
+func TestClosureReduceItem(t *testing.T) {
+	g := buildAugmentedArith(t)
+	r := findRule(t, g, "term", "NUM")
+	closed := Closure(NewItemSet(Item{RuleIndex: r.Index, Dot: 1}), g)
+	if closed.Len() != 1 {
+		t.Errorf("closure of reduce item should stay at 1, got %d", closed.Len())
+	}
+}
+
+func TestItemSet(t *testing.T) {
+	s := NewItemSet()
+	s.Add(Item{RuleIndex: 2, Dot: 0})
+	s.Add(Item{RuleIndex: 1, Dot: 1})
+	if s.Add(Item{RuleIndex: 2, Dot: 0}) {
+		t.Error("Add of a duplicate returned true")
+	}
+	s.Add(Item{RuleIndex: 1, Dot: 0})
+	if s.Len() != 3 {
+		t.Errorf("ItemSet.Len() = %d, want 3", s.Len())
+	}
+	want := NewItemSet(Item{RuleIndex: 1, Dot: 0}, Item{RuleIndex: 1, Dot: 1}, Item{RuleIndex: 2, Dot: 0})
+	if !s.Equal(want) {
+		t.Errorf("items = %v, want %v", s.Items, want.Items)
+	}
+}
+
+func TestSymbolAfterDot(t *testing.T) {
+	g := buildAugmentedArith(t)
+	r := findRule(t, g, "expr", "expr", "PLUS", "term")
+	if sym := (Item{RuleIndex: r.Index, Dot: 1}).SymbolAfterDot(g); sym == nil || sym.Name != "PLUS" {
+		t.Errorf("SymbolAfterDot = %v, want PLUS", sym)
+	}
+	item := Item{RuleIndex: findRule(t, g, "term", "NUM").Index, Dot: 1}
+	if item.SymbolAfterDot(g) != nil || !item.IsReduce(g) {
+		t.Error("term ::= NUM * should be a reduce item with no symbol after the dot")
+	}
+}
+
+func TestGoto(t *testing.T) {
+	g := buildAugmentedArith(t)
+	state0 := Closure(NewItemSet(Item{RuleIndex: g.AcceptRule.Index, Dot: 0}), g)
+	term := findRule(t, g, "term", "NUM")
+	if got := Goto(state0, lookup(t, g, "NUM"), g); !got.Contains(Item{RuleIndex: term.Index, Dot: 1}) {
+		t.Error("GOTO(state0, NUM) should contain term ::= NUM *")
+	}
+	if got := Goto(state0, lookup(t, g, "PLUS"), g); got.Len() != 0 {
+		t.Errorf("GOTO(state0, PLUS) has %d items, want 0", got.Len())
+	}
+}
+
+func TestBuildCanonicalArith(t *testing.T) {
+	g := buildAugmentedArith(t)
+	a := BuildCanonical(g)
+	var got []string
+	for _, s := range a.States {
+		var kernel []string
+		for _, item := range s.Kernel(g) {
+			kernel = append(kernel, item.String(g))
+		}
+		got = append(got, fmt.Sprintf("%d: %s", s.ID, strings.Join(kernel, " | ")))
+	}
+	want := []string{
+		"0: $accept ::= * expr.",
+		"1: term ::= NUM *.",
+		"2: expr ::= expr * PLUS term. | $accept ::= expr *.",
+		"3: expr ::= term *.",
+		"4: expr ::= expr PLUS * term.",
+		"5: expr ::= expr PLUS term *.",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("states:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, tr := range a.Transitions {
+		if a.Next(tr.From, tr.Symbol) != tr.To {
+			t.Errorf("Next(%d, %s) = %d, want %d", tr.From, tr.Symbol.Name, a.Next(tr.From, tr.Symbol), tr.To)
+		}
+		if a.States[tr.To].Symbol != tr.Symbol {
+			t.Errorf("state %d entered on %s, want %s", tr.To, a.States[tr.To].Symbol.Name, tr.Symbol.Name)
+		}
+	}
+	if len(a.Transitions) != 6 {
+		t.Errorf("%d transitions, want 6", len(a.Transitions))
+	}
+}
+
+func TestBuildCanonicalDeterministic(t *testing.T) {
+	src := `
+program ::= stmts.
+stmts ::= stmts stmt.
+stmts ::= .
+stmt ::= ID ASSIGN expr SEMI.
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+expr ::= expr PLUS expr.
+expr ::= ID.
+expr ::= LPAREN expr RPAREN.
+`
+	first := render(BuildLALR(mustGrammar(t, src)))
+	for i := 0; i < 10; i++ {
+		if render(BuildLALR(mustGrammar(t, src))) != first {
+			t.Fatal("automaton differs between builds")
+		}
+	}
+}
+
+// render lists an automaton's items, lookaheads and transitions.
+func render(a *Automaton) string {
+	var sb strings.Builder
+	for _, s := range a.States {
+		for _, item := range s.LAItems {
+			fmt.Fprintf(&sb, "%d %s %v\n", s.ID, item.String(a.Grammar), sortedKeys(item.Lookahead))
+		}
+	}
+	for _, tr := range a.Transitions {
+		fmt.Fprintf(&sb, "%d %s %d\n", tr.From, tr.Symbol.Name, tr.To)
+	}
+	return sb.String()
+}
+
+func sortedKeys(set map[int]bool) []int {
+	var keys []int
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestAcceptRuleLookahead(t *testing.T) {
+	g := buildAugmentedArith(t)
+	a := BuildLALR(g)
+	got := lookaheads(a, g.AcceptRule)
+	if len(got) != 1 || got[0] != "2: $" {
+		t.Errorf("$accept lookaheads = %q, want [\"2: $\"]", got)
+	}
+}
+
+func TestReduceItemLookahead(t *testing.T) {
+	g := buildAugmentedArith(t)
+	a := BuildLALR(g)
+	got := lookaheads(a, findRule(t, g, "term", "NUM"))
+	if len(got) != 1 || got[0] != "1: $ PLUS" {
+		t.Errorf("term ::= NUM * lookaheads = %q, want [\"1: $ PLUS\"]", got)
+	}
+}
+
+func TestAutomatonHasLAItems(t *testing.T) {
+	g := buildAugmentedArith(t)
+	a := BuildLALR(g)
+	for _, s := range a.States {
+		if len(s.LAItems) != s.Items.Len() {
+			t.Errorf("state %d has %d LAItems, want %d", s.ID, len(s.LAItems), s.Items.Len())
+		}
+		for _, item := range s.LAItems {
+			if item.IsReduce(g) && len(item.Lookahead) == 0 {
+				t.Errorf("state %d: %s has no lookahead", s.ID, item.String(g))
+			}
+		}
+	}
+}
+
+// TestLALRNotSLR uses the classic grammar that is LALR(1) but not SLR(1).
+// FOLLOW(R) contains EQ, but in the state reached on L from the start
+// state, R ::= L * can only be followed by $.
+func TestLALRNotSLR(t *testing.T) {
+	g := mustGrammar(t, `
+s ::= l EQ r.
+s ::= r.
+l ::= STAR r.
+l ::= ID.
+r ::= l.
+`)
+	a := BuildLALR(g)
+	nullable := analysis.ComputeNullable(g)
+	follow := analysis.ComputeFollow(g, nullable, analysis.ComputeFirst(g, nullable))
+	eq := lookup(t, g, "EQ")
+	if !follow[lookup(t, g, "r").ID][eq.ID] {
+		t.Fatal("FOLLOW(r) should contain EQ")
+	}
+	rl := findRule(t, g, "r", "l")
+	to := a.Next(0, lookup(t, g, "l"))
+	for _, item := range a.States[to].Reductions(g) {
+		if item.RuleIndex == rl.Index && item.Lookahead[eq.ID] {
+			t.Errorf("state %d: r ::= l * should not have EQ in its lookahead", to)
+		}
+	}
+	for _, la := range lookaheads(a, rl) {
+		if strings.HasPrefix(la, fmt.Sprintf("%d:", to)) && la != fmt.Sprintf("%d: $", to) {
+			t.Errorf("r ::= l * lookahead = %q, want {$}", la)
+		}
+	}
+}
+
+// TestLookaheadNullable exercises the reads and includes relations:
+// ε-rules are reduced on whatever can follow them through nullable
+// symbols, and the lookahead of a rule ending in a nullable symbol flows
+// into the symbol before it.
+func TestLookaheadNullable(t *testing.T) {
+	g := mustGrammar(t, `
+s ::= a b C.
+a ::= A.
+a ::= .
+b ::= B.
+b ::= .
+s ::= D e.
+e ::= E f.
+f ::= .
+`)
+	a := BuildLALR(g)
+	for _, tc := range []struct {
+		rule *grammar.Rule
+		want string
+	}{
+		{findRule(t, g, "a"), "C B"},
+		{findRule(t, g, "b"), "C"},
+		{findRule(t, g, "f"), "$"},
+		{findRule(t, g, "e", "E", "f"), "$"},
+	} {
+		got := lookaheads(a, tc.rule)
+		if len(got) != 1 || strings.SplitN(got[0], ": ", 2)[1] != tc.want {
+			t.Errorf("%s lookaheads = %q, want %q", tc.rule, got, tc.want)
+		}
+	}
+}
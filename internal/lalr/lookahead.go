@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// BuildLALR builds the LR(0) automaton for a finalized grammar and
+// annotates it with LALR(1) lookaheads.
+func BuildLALR(g *grammar.Grammar) *Automaton {
+	a := BuildCanonical(g)
+	ComputeLookaheads(a, g, analysis.ComputeNullable(g))
+	return a
+}
+
+// ComputeLookaheads fills in LAItems for every state of the automaton,
+// giving each reduce item its LALR(1) lookahead set.
+//
+// The sets are computed with the relations of DeRemer and Pennello
+// ("Efficient Computation of LALR(1) Look-Ahead Sets", 1982), defined
+// over the nonterminal transitions (p, A) of the automaton:
+//
+//	DR(p, A)      terminals t such that p -A-> r -t->
+//	(p, A) reads (r, C)      if p -A-> r -C-> and C is nullable
+//	(p, A) includes (p', B)  if B ::= β A γ, γ is nullable and p' -β-> p
+//	(q, A ::= ω) lookback (p, A)  if p -ω-> q
+//
+// Read is DR closed under reads, Follow is Read closed under includes, and
+// the lookahead of a reduce item is the union of Follow over its lookback
+// transitions. Both closures use the digraph algorithm, which handles the
+// cycles in a relation by collapsing its strongly connected components, so
+// each set is computed once rather than iterated to a fixed point. The
+// augmented rule $accept ::= S * always has the lookahead {$}.
+func ComputeLookaheads(a *Automaton, g *grammar.Grammar, nullable map[int]bool) {
+	nterm := g.NumTerminals()
+
+	// Number the nonterminal transitions.
+	type ntTrans struct {
+		from, to int
+		sym      *grammar.Symbol
+	}
+	var trans []ntTrans
+	transIndex := map[[2]int]int{} // (from, symbol ID) → transition number
+	for _, t := range a.Transitions {
+		if t.Symbol.Kind == grammar.SymbolNonterminal {
+			transIndex[[2]int{t.From, t.Symbol.ID}] = len(trans)
+			trans = append(trans, ntTrans{from: t.From, to: t.To, sym: t.Symbol})
+		}
+	}
+
+	// DR and reads.
+	read := make([]bitset, len(trans))
+	reads := make([][]int, len(trans))
+	for i, t := range trans {
+		read[i] = newBitset(nterm)
+		if t.from == 0 && t.sym == g.Start {
+			// $accept ::= * S $ is implied: $ follows S from the start state.
+			read[i].set(g.EOF.ID)
+		}
+		for sym := range a.next[t.to] {
+			if sym < nterm {
+				read[i].set(sym)
+			} else if nullable[sym] {
+				reads[i] = append(reads[i], transIndex[[2]int{t.to, sym}])
+			}
+		}
+	}
+	digraph(reads, read)
+
+	// includes and lookback. For each transition (p', B) and each rule
+	// B ::= X1 ... Xn, walk the rule from p'. Every nonterminal Xi with a
+	// nullable suffix makes (p, Xi) include (p', B); the state reached at
+	// the end is where the rule is reduced, with (p', B) as lookback.
+	includes := make([][]int, len(trans))
+	type reduceKey struct{ state, rule int }
+	lookback := map[reduceKey][]int{}
+	byLHS := rulesByLHS(g)
+	for j, t := range trans {
+		for _, r := range byLHS[t.sym.ID] {
+			p := t.from
+			for i, x := range r.RHS {
+				if x.Kind == grammar.SymbolNonterminal && allNullable(r.RHS[i+1:], nullable) {
+					k := transIndex[[2]int{p, x.ID}]
+					includes[k] = append(includes[k], j)
+				}
+				p = a.next[p][x.ID]
+			}
+			key := reduceKey{p, r.Index}
+			lookback[key] = append(lookback[key], j)
+		}
+	}
+	follow := read
+	digraph(includes, follow)
+
+	for _, s := range a.States {
+		s.LAItems = make([]LAItem, 0, s.Items.Len())
+		for _, item := range s.Items.Items {
+			la := LAItem{Item: item}
+			if item.IsReduce(g) {
+				set := newBitset(nterm)
+				if g.Rules[item.RuleIndex] == g.AcceptRule {
+					set.set(g.EOF.ID)
+				}
+				for _, j := range lookback[reduceKey{s.ID, item.RuleIndex}] {
+					set.union(follow[j])
+				}
+				la.Lookahead = map[int]bool{}
+				for _, t := range set.members() {
+					la.Lookahead[t] = true
+				}
+			}
+			s.LAItems = append(s.LAItems, la)
+		}
+	}
+}
+
+// digraph computes F(x) = F'(x) ∪ ⋃{F(y) : x R y} for every x, where F'
+// is the initial value of f and R is given by rel, using the algorithm of
+// DeRemer and Pennello. Members of a strongly connected component end up
+// with the same set.
+func digraph(rel [][]int, f []bitset) {
+	n := len(f)
+	depth := make([]int, n)
+	var stack []int
+	var traverse func(x int)
+	traverse = func(x int) {
+		stack = append(stack, x)
+		d := len(stack)
+		depth[x] = d
+		for _, y := range rel[x] {
+			if depth[y] == 0 {
+				traverse(y)
+			}
+			if depth[y] < depth[x] {
+				depth[x] = depth[y]
+			}
+			f[x].union(f[y])
+		}
+		if depth[x] == d {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				depth[top] = n + 1 // done; never lowers another depth
+				if top == x {
+					break
+				}
+				copy(f[top], f[x])
+			}
+		}
+	}
+	for x := 0; x < n; x++ {
+		if depth[x] == 0 {
+			traverse(x)
+		}
+	}
+}
+
+// allNullable reports whether every symbol in seq is nullable.
+func allNullable(seq []*grammar.Symbol, nullable map[int]bool) bool {
+	for _, s := range seq {
+		if !nullable[s.ID] {
+			return false
+		}
+	}
+	return true
+}
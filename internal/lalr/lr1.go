@@ -0,0 +1,183 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/analysis"
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// lr1State is a state of the canonical LR(1) automaton. Its items are
+// the closed LR(0) core, with the LR(1) lookaheads of each item in la.
+type lr1State struct {
+	items ItemSet
+	la    []bitset        // parallel to items.Items
+	sym   *grammar.Symbol // symbol shifted to enter the state
+	next  map[int]int     // symbol ID → state
+}
+
+// lr1Builder holds what the LR(1) closure needs about the grammar.
+type lr1Builder struct {
+	g        *grammar.Grammar
+	byLHS    map[int][]*grammar.Rule
+	nullable map[int]bool
+	first    []bitset // by symbol ID
+	nterm    int
+}
+
+// BuildLR1 builds the canonical LR(1) automaton of Knuth: states are told
+// apart by their lookaheads as well as their cores, so no lookahead is
+// ever merged. The automaton can be many times larger than the LALR(1)
+// one. States are numbered as in BuildCanonical.
+func BuildLR1(g *grammar.Grammar) *Automaton {
+	states := buildLR1States(g)
+	a := &Automaton{Grammar: g, Mode: ModeCanonical}
+	for id, s := range states {
+		a.States = append(a.States, &State{ID: id, Items: s.items, Symbol: s.sym, LAItems: reduceLookaheads(g, s.items, s.la)})
+		a.next = append(a.next, s.next)
+	}
+	a.Transitions = transitions(a)
+	return a
+}
+
+func buildLR1States(g *grammar.Grammar) []*lr1State {
+	nullable := analysis.ComputeNullable(g)
+	b := &lr1Builder{g: g, byLHS: rulesByLHS(g), nullable: nullable, nterm: g.NumTerminals()}
+	first := analysis.ComputeFirst(g, nullable)
+	b.first = make([]bitset, g.Symbols.NumSymbols())
+	for id := range b.first {
+		b.first[id] = newBitset(b.nterm)
+		for t := range first[id] {
+			b.first[id].set(t)
+		}
+	}
+
+	var states []*lr1State
+	index := map[string]int{}
+	add := func(kernel []Item, la []bitset, sym *grammar.Symbol) int {
+		k := lr1Key(kernel, la)
+		if id, ok := index[k]; ok {
+			return id
+		}
+		id := len(states)
+		index[k] = id
+		items, itemLA := b.closure(kernel, la)
+		states = append(states, &lr1State{items: items, la: itemLA, sym: sym, next: map[int]int{}})
+		return id
+	}
+	eof := newBitset(b.nterm)
+	eof.set(g.EOF.ID)
+	add([]Item{{RuleIndex: g.AcceptRule.Index, Dot: 0}}, []bitset{eof}, nil)
+
+	for id := 0; id < len(states); id++ {
+		s := states[id]
+		for _, sym := range symbolsAfterDot(s.items, g) {
+			var kernel ItemSet
+			for _, item := range s.items.Items {
+				if item.SymbolAfterDot(g) == sym {
+					kernel.Add(Item{RuleIndex: item.RuleIndex, Dot: item.Dot + 1})
+				}
+			}
+			la := make([]bitset, kernel.Len())
+			for i := range la {
+				la[i] = newBitset(b.nterm)
+			}
+			for i, item := range s.items.Items {
+				if item.SymbolAfterDot(g) == sym {
+					la[kernel.index(Item{RuleIndex: item.RuleIndex, Dot: item.Dot + 1})].union(s.la[i])
+				}
+			}
+			s.next[sym.ID] = add(kernel.Items, la, sym)
+		}
+	}
+	return states
+}
+
+// closure computes the LR(1) closure of a kernel: for [A ::= α * B β, a],
+// every rule B ::= γ gets [B ::= * γ, FIRST(β a)]. Lookaheads are added
+// until nothing changes.
+func (b *lr1Builder) closure(kernel []Item, kernelLA []bitset) (ItemSet, []bitset) {
+	items := closure(ItemSet{Items: kernel}, b.g, b.byLHS)
+	la := make([]bitset, items.Len())
+	for i := range la {
+		la[i] = newBitset(b.nterm)
+	}
+	for i, item := range kernel {
+		la[items.index(item)].union(kernelLA[i])
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, item := range items.Items {
+			sym := item.SymbolAfterDot(b.g)
+			if sym == nil || sym.Kind == grammar.SymbolTerminal {
+				continue
+			}
+			rest := b.g.Rules[item.RuleIndex].RHS[item.Dot+1:]
+			gen := newBitset(b.nterm)
+			for _, x := range rest {
+				gen.union(b.first[x.ID])
+				if !b.nullable[x.ID] {
+					break
+				}
+			}
+			if allNullable(rest, b.nullable) {
+				gen.union(la[i])
+			}
+			for _, r := range b.byLHS[sym.ID] {
+				j := items.index(Item{RuleIndex: r.Index, Dot: 0})
+				if la[j].addAll(gen) {
+					changed = true
+				}
+			}
+		}
+	}
+	return items, la
+}
+
+// lr1Key identifies a kernel with its lookaheads.
+func lr1Key(kernel []Item, la []bitset) string {
+	var sb strings.Builder
+	for i, item := range kernel {
+		sb.WriteString(strconv.Itoa(item.RuleIndex))
+		sb.WriteByte('.')
+		sb.WriteString(strconv.Itoa(item.Dot))
+		for _, t := range la[i].members() {
+			sb.WriteByte(',')
+			sb.WriteString(strconv.Itoa(t))
+		}
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// reduceLookaheads returns the LAItems of a state, keeping lookaheads on
+// reduce items only, as ComputeLookaheads does.
+func reduceLookaheads(g *grammar.Grammar, items ItemSet, la []bitset) []LAItem {
+	list := make([]LAItem, 0, items.Len())
+	for i, item := range items.Items {
+		la2 := LAItem{Item: item}
+		if item.IsReduce(g) {
+			la2.Lookahead = map[int]bool{}
+			for _, t := range la[i].members() {
+				la2.Lookahead[t] = true
+			}
+		}
+		list = append(list, la2)
+	}
+	return list
+}
+
+// transitions lists the automaton's edges from its next maps, ordered by
+// source state and then symbol ID.
+func transitions(a *Automaton) []Transition {
+	var list []Transition
+	for from := range a.States {
+		for _, sym := range symbolsAfterDot(a.States[from].Items, a.Grammar) {
+			list = append(list, Transition{From: from, Symbol: sym, To: a.next[from][sym.ID]})
+		}
+	}
+	return list
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "testing"
+
+// notLALR is LR(1) but not LALR(1): the states reached on E after "a e"
+// and "b e" have the same core, and merging them gives a reduce/reduce
+// conflict on both C and D.
+const notLALR = `
+s ::= A e C.
+s ::= A f D.
+s ::= B f C.
+s ::= B e D.
+e ::= E.
+f ::= E.
+`
+
+// mergedLookaheads merges the LR(1) lookaheads of each reduce item by core.
+func mergedLookaheads(a *Automaton) map[string]map[Item]map[int]bool {
+	merged := map[string]map[Item]map[int]bool{}
+	for _, s := range a.States {
+		k := s.Items.key()
+		if merged[k] == nil {
+			merged[k] = map[Item]map[int]bool{}
+		}
+		for _, item := range s.Reductions(a.Grammar) {
+			if merged[k][item.Item] == nil {
+				merged[k][item.Item] = map[int]bool{}
+			}
+			for t := range item.Lookahead {
+				merged[k][item.Item][t] = true
+			}
+		}
+	}
+	return merged
+}
+
+// TestLALRMatchesMergedLR1 checks the DeRemer-Pennello lookaheads against
+// the definition of LALR(1): canonical LR(1) lookaheads merged by core.
+func TestLALRMatchesMergedLR1(t *testing.T) {
+	for _, src := range []string{
+		`expr ::= expr PLUS term. expr ::= term. term ::= NUM.`,
+		`s ::= l EQ r. s ::= r. l ::= STAR r. l ::= ID. r ::= l.`,
+		`s ::= a b C. a ::= A. a ::= . b ::= B. b ::= . s ::= D e. e ::= E f. f ::= . f ::= F s.`,
+		`program ::= stmts. stmts ::= stmts stmt. stmts ::= . stmt ::= IF x THEN stmt. stmt ::= IF x THEN stmt ELSE stmt. stmt ::= x SEMI. x ::= x PLUS x. x ::= ID. x ::= LPAREN x RPAREN.`,
+		notLALR,
+	} {
+		g := mustGrammar(t, src)
+		lalr := BuildLALR(g)
+		want := mergedLookaheads(BuildLR1(g))
+		if len(want) != len(lalr.States) {
+			t.Errorf("%s: %d LALR states, %d LR(1) cores", src, len(lalr.States), len(want))
+			continue
+		}
+		for _, s := range lalr.States {
+			for _, item := range s.Reductions(g) {
+				w := want[s.Items.key()][item.Item]
+				if len(w) != len(item.Lookahead) {
+					t.Errorf("state %d: %s: lookahead %v, want %v", s.ID, item.String(g), item.Lookahead, w)
+					continue
+				}
+				for la := range w {
+					if !item.Lookahead[la] {
+						t.Errorf("state %d: %s: lookahead %v, want %v", s.ID, item.String(g), item.Lookahead, w)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	g := mustGrammar(t, notLALR)
+	lalr := BuildLALR(g)
+	report := DetectAndResolveConflicts(lalr, g)
+	if report.ReduceReduce() != 2 {
+		t.Fatalf("LALR: %d reduce/reduce conflicts, want 2", report.ReduceReduce())
+	}
+	merged := MergeConflicts(lalr, g)
+	if len(merged) != 2 {
+		t.Fatalf("%d merge conflicts, want 2", len(merged))
+	}
+	for _, c := range merged {
+		if c.Kind != ReduceReduce {
+			t.Errorf("%s: want reduce/reduce", c.String(g))
+		}
+	}
+
+	for _, mode := range []Mode{ModeIELR, ModeCanonical} {
+		a := Build(g, mode)
+		if r := DetectAndResolveConflicts(a, g); len(r.Conflicts) != 0 {
+			t.Errorf("%s: %d conflicts, want 0", mode, len(r.Conflicts))
+		}
+		if len(a.States) != len(lalr.States)+1 {
+			t.Errorf("%s: %d states, want %d", mode, len(a.States), len(lalr.States)+1)
+		}
+	}
+}
+
+// TestMergeConflictsGenuine checks that a conflict canonical LR(1) has too
+// is not blamed on merging.
+func TestMergeConflictsGenuine(t *testing.T) {
+	g := mustGrammar(t, `s ::= a. s ::= b. a ::= X. b ::= X.`)
+	if merged := MergeConflicts(BuildLALR(g), g); len(merged) != 0 {
+		t.Errorf("%d merge conflicts, want 0", len(merged))
+	}
+}
+
+// TestIELRMatchesLALR checks that IELR builds exactly the LALR automaton
+// when merging causes no conflicts, and that canonical LR(1) is larger.
+func TestIELRMatchesLALR(t *testing.T) {
+	g := mustGrammar(t, `
+%left PLUS.
+%left TIMES.
+program ::= stmts.
+stmts ::= stmts stmt.
+stmts ::= .
+stmt ::= ID ASSIGN expr SEMI.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= LPAREN expr RPAREN.
+expr ::= ID.
+`)
+	lalr, ielr := BuildLALR(g), BuildIELR(g)
+	if got, want := render(ielr), render(lalr); got != want {
+		t.Errorf("IELR automaton:\n%s\nwant:\n%s", got, want)
+	}
+	if n := len(BuildLR1(g).States); n <= len(lalr.States) {
+		t.Errorf("canonical LR(1) has %d states, want more than %d", n, len(lalr.States))
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, m := range []Mode{ModeLALR, ModeIELR, ModeCanonical} {
+		if got, err := ParseMode(m.String()); err != nil || got != m {
+			t.Errorf("ParseMode(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+	if _, err := ParseMode("slr"); err == nil {
+		t.Error("ParseMode(slr) should fail")
+	}
+}
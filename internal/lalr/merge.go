@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// MergeConflicts returns the conflicts of an LALR(1) automaton that are
+// caused by merging LR(1) states: those for which no canonical LR(1) state
+// with the same core has a conflict of the same kind, on the same terminal,
+// between the same rules. Building with ModeIELR or ModeCanonical avoids
+// them.
+func MergeConflicts(a *Automaton, g *grammar.Grammar) []Conflict {
+	lalr := DetectAndResolveConflicts(a, g)
+	if len(lalr.Conflicts) == 0 {
+		return nil
+	}
+	lr1 := BuildLR1(g)
+	type sig struct {
+		core         string
+		kind         ConflictKind
+		terminal     int
+		rule1, rule2 int
+	}
+	signature := func(a *Automaton, c Conflict) sig {
+		s := sig{core: a.States[c.StateID].Items.key(), kind: c.Kind, terminal: c.Terminal.ID, rule1: c.ReduceItem.RuleIndex, rule2: -1}
+		if c.ReduceItem2 != nil {
+			s.rule2 = c.ReduceItem2.RuleIndex
+		}
+		return s
+	}
+	canonical := map[sig]bool{}
+	for _, c := range DetectAndResolveConflicts(lr1, g).Conflicts {
+		canonical[signature(lr1, c)] = true
+	}
+	var list []Conflict
+	for _, c := range lalr.Conflicts {
+		if !canonical[signature(a, c)] {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"fmt"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// Mode selects how the automaton is constructed.
+type Mode int
+
+const (
+	// ModeLALR merges all LR(1) states with the same core (LALR(1)).
+	ModeLALR Mode = iota
+	// ModeIELR merges states with the same core unless merging would add
+	// a conflict, giving LALR-sized tables without merge-induced conflicts.
+	ModeIELR
+	// ModeCanonical never merges (Knuth's canonical LR(1)).
+	ModeCanonical
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeLALR:
+		return "lalr"
+	case ModeIELR:
+		return "ielr"
+	case ModeCanonical:
+		return "canonical"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ParseMode returns the mode named by s: "lalr", "ielr" or "canonical".
+func ParseMode(s string) (Mode, error) {
+	for _, m := range []Mode{ModeLALR, ModeIELR, ModeCanonical} {
+		if s == m.String() {
+			return m, nil
+		}
+	}
+	return ModeLALR, fmt.Errorf("unknown LR construction %q (want lalr, ielr or canonical)", s)
+}
+
+// Build constructs the automaton for a finalized grammar in the given mode,
+// with lookaheads.
+func Build(g *grammar.Grammar, mode Mode) *Automaton {
+	switch mode {
+	case ModeIELR:
+		return BuildIELR(g)
+	case ModeCanonical:
+		return BuildLR1(g)
+	}
+	return BuildLALR(g)
+}
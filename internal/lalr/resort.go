@@ -0,0 +1,69 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "sort"
+
+// Resort renumbers the states of an automaton with lookaheads the way
+// Lemon does before packing its tables: state 0 stays first and the other
+// states follow from the most to the fewest table entries, counting
+// terminal actions other than the default reduction and gotos. States with
+// the same count keep their construction order, so the numbering depends
+// only on the automaton.
+func Resort(a *Automaton) {
+	g := a.Grammar
+	table := BuildTables(a, g)
+	entries := make([]int, len(a.States))
+	for s, row := range table.Action {
+		def := defaultReduction(row)
+		for _, act := range row {
+			if act.Kind != ActionError && act != def {
+				entries[s]++
+			}
+		}
+	}
+	for _, tr := range a.Transitions {
+		if !tr.Symbol.IsTerminal() {
+			entries[tr.From]++
+		}
+	}
+
+	order := make([]int, len(a.States)) // new ID → old ID
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order[1:], func(i, j int) bool {
+		return entries[order[1+i]] > entries[order[1+j]]
+	})
+	renumber(a, order)
+}
+
+// renumber gives state order[i] the ID i.
+func renumber(a *Automaton, order []int) {
+	newID := make([]int, len(order))
+	for id, old := range order {
+		newID[old] = id
+	}
+	states := make([]*State, len(order))
+	next := make([]map[int]int, len(order))
+	for id, old := range order {
+		states[id] = a.States[old]
+		states[id].ID = id
+		next[id] = map[int]int{}
+		for sym, to := range a.next[old] {
+			next[id][sym] = newID[to]
+		}
+	}
+	for i := range a.Transitions {
+		tr := &a.Transitions[i]
+		tr.From, tr.To = newID[tr.From], newID[tr.To]
+	}
+	sort.SliceStable(a.Transitions, func(i, j int) bool {
+		ti, tj := a.Transitions[i], a.Transitions[j]
+		if ti.From != tj.From {
+			return ti.From < tj.From
+		}
+		return ti.Symbol.ID < tj.Symbol.ID
+	})
+	a.States, a.next = states, next
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "testing"
+
+func TestResort(t *testing.T) {
+	g := mustGrammar(t, `
+%left PLUS.
+%left TIMES.
+stmt ::= ID ASSIGN expr SEMI.
+stmt ::= expr SEMI.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= LPAREN expr RPAREN.
+expr ::= ID.
+expr ::= NUM.
+`)
+	before := BuildLALR(g)
+	a := BuildLALR(g)
+	Resort(a)
+
+	if a.States[0].Symbol != nil || !a.States[0].Items.Contains(Item{RuleIndex: g.AcceptRule.Index}) {
+		t.Error("state 0 is no longer the initial state")
+	}
+	for id, s := range a.States {
+		if s.ID != id {
+			t.Errorf("state at %d has ID %d", id, s.ID)
+		}
+	}
+	for _, tr := range a.Transitions {
+		if a.Next(tr.From, tr.Symbol) != tr.To {
+			t.Errorf("Next(%d, %s) = %d, want %d", tr.From, tr.Symbol.Name, a.Next(tr.From, tr.Symbol), tr.To)
+		}
+		if want := Goto(a.States[tr.From].Items, tr.Symbol, g); !a.States[tr.To].Items.Equal(want) {
+			t.Errorf("transition %d -%s-> %d does not match GOTO", tr.From, tr.Symbol.Name, tr.To)
+		}
+	}
+	if len(a.Transitions) != len(before.Transitions) {
+		t.Errorf("%d transitions, want %d", len(a.Transitions), len(before.Transitions))
+	}
+
+	// Entries never increase down the list, and the tables are the same
+	// up to the renumbering.
+	table, old := Compress(BuildTables(a, g)), Compress(BuildTables(before, g))
+	count := func(c *CompressedTable, s int) int {
+		n := 0
+		for sym := 0; sym < len(g.Symbols.All()); sym++ {
+			base := c.ActionBase[s]
+			if sym >= c.NumTerminals {
+				base = c.GotoBase[s]
+			}
+			if i := base + sym; base != NoBase && i >= 0 && i < len(c.Check) && c.Check[i] == sym {
+				n++
+			}
+		}
+		return n
+	}
+	for s := 2; s < len(a.States); s++ {
+		if count(table, s-1) < count(table, s) {
+			t.Errorf("state %d has fewer entries than state %d", s-1, s)
+		}
+	}
+	if table.Size() > old.Size() {
+		t.Errorf("resorted tables are larger: %d > %d", table.Size(), old.Size())
+	}
+
+	// Resorting is deterministic and settles after one pass.
+	again := BuildLALR(g)
+	Resort(again)
+	if render(again) != render(a) {
+		t.Error("Resort is not deterministic")
+	}
+	Resort(again)
+	if render(again) != render(a) {
+		t.Error("a second Resort changed the order")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "github.com/mdhender/guanabana/internal/grammar"
+
+// State is a state of the LR automaton.
+type State struct {
+	ID     int
+	Items  ItemSet         // closed item set
+	Symbol *grammar.Symbol // symbol shifted to enter the state; nil for state 0
+
+	// LAItems holds every item of the state with its lookahead set.
+	// It is filled in by ComputeLookaheads; only reduce items have
+	// lookaheads.
+	LAItems []LAItem
+}
+
+// Kernel returns the state's kernel items.
+func (s *State) Kernel(g *grammar.Grammar) []Item {
+	var kernel []Item
+	for _, item := range s.Items.Items {
+		if item.IsKernel(g) {
+			kernel = append(kernel, item)
+		}
+	}
+	return kernel
+}
+
+// Reductions returns the state's reduce items with their lookaheads.
+func (s *State) Reductions(g *grammar.Grammar) []LAItem {
+	var list []LAItem
+	for _, item := range s.LAItems {
+		if item.IsReduce(g) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// LAItem is an LR(0) item annotated with an LALR(1) lookahead set.
+type LAItem struct {
+	Item
+	Lookahead map[int]bool // set of terminal symbol IDs
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import (
+	"slices"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// ParseTable holds the ACTION and GOTO tables that drive a generated
+// parser.
+type ParseTable struct {
+	NumStates       int
+	NumTerminals    int
+	NumNonterminals int
+	Action          [][]Action      // [stateID][terminalID]
+	Goto            [][]int         // [stateID][nonterminalID - NumTerminals]; -1 means none
+	Rules           []*grammar.Rule // rule table for reduce actions
+
+	// Nonassoc lists, per state ID, the terminals whose ACTION entry is an
+	// error because of %nonassoc rather than because nothing applies.
+	Nonassoc map[int][]int
+}
+
+// BuildTables fills the ACTION and GOTO tables of an automaton with
+// lookaheads. Conflicts are settled as DetectAndResolveConflicts reports
+// them, so every cell holds exactly one action.
+func BuildTables(a *Automaton, g *grammar.Grammar) *ParseTable {
+	nterm := g.NumTerminals()
+	t := &ParseTable{
+		NumStates:       len(a.States),
+		NumTerminals:    nterm,
+		NumNonterminals: g.Symbols.NumSymbols() - nterm,
+		Rules:           g.Rules,
+		Nonassoc:        map[int][]int{},
+	}
+	for _, s := range a.States {
+		actions, conflicts := stateActions(a, s, g)
+		t.Action = append(t.Action, actions)
+		for _, c := range conflicts {
+			if c.Resolution == "error" && !slices.Contains(t.Nonassoc[s.ID], c.Terminal.ID) {
+				t.Nonassoc[s.ID] = append(t.Nonassoc[s.ID], c.Terminal.ID)
+			}
+		}
+		gotos := make([]int, t.NumNonterminals)
+		for i := range gotos {
+			gotos[i] = -1
+		}
+		t.Goto = append(t.Goto, gotos)
+	}
+	for _, tr := range a.Transitions {
+		if !tr.Symbol.IsTerminal() {
+			t.Goto[tr.From][tr.Symbol.ID-nterm] = tr.To
+		}
+	}
+	return t
+}
+
+// Size returns the number of cells in the ACTION and GOTO tables.
+func (t *ParseTable) Size() int {
+	return t.NumStates * (t.NumTerminals + t.NumNonterminals)
+}
+
+// GotoState returns the state entered from state after reducing to the
+// nonterminal nt, or -1 if there is none.
+func (t *ParseTable) GotoState(state int, nt *grammar.Symbol) int {
+	return t.Goto[state][nt.ID-t.NumTerminals]
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package lalr
+
+import "testing"
+
+func TestBuildTablesArith(t *testing.T) {
+	g := buildAugmentedArith(t)
+	table := BuildTables(BuildLALR(g), g)
+	if table.NumStates != 6 || table.NumTerminals != 3 || table.NumNonterminals != 3 {
+		t.Fatalf("dimensions = %d states, %d terminals, %d nonterminals, want 6, 3, 3",
+			table.NumStates, table.NumTerminals, table.NumNonterminals)
+	}
+	plus, num := lookup(t, g, "PLUS"), lookup(t, g, "NUM")
+	eof := g.Symbols.Symbol(0)
+	term := findRule(t, g, "term", "NUM")
+	for _, tc := range []struct {
+		state, terminal int
+		want            Action
+	}{
+		{0, num.ID, Action{Kind: ActionShift, State: 1}},
+		{0, plus.ID, Action{Kind: ActionError}},
+		{1, plus.ID, Action{Kind: ActionReduce, RuleIndex: term.Index}},
+		{1, eof.ID, Action{Kind: ActionReduce, RuleIndex: term.Index}},
+		{2, eof.ID, Action{Kind: ActionAccept}},
+		{2, plus.ID, Action{Kind: ActionShift, State: 4}},
+	} {
+		if got := table.Action[tc.state][tc.terminal]; got != tc.want {
+			t.Errorf("ACTION[%d, %s] = %v, want %v", tc.state, g.Symbols.Symbol(tc.terminal).Name, got, tc.want)
+		}
+	}
+	expr, termSym := lookup(t, g, "expr"), lookup(t, g, "term")
+	if got := table.GotoState(0, expr); got != 2 {
+		t.Errorf("GOTO[0, expr] = %d, want 2", got)
+	}
+	if got := table.GotoState(4, termSym); got != 5 {
+		t.Errorf("GOTO[4, term] = %d, want 5", got)
+	}
+	if got := table.GotoState(1, expr); got != -1 {
+		t.Errorf("GOTO[1, expr] = %d, want -1", got)
+	}
+}
+
+func TestBuildTablesResolution(t *testing.T) {
+	g := mustGrammar(t, `
+%left PLUS.
+%nonassoc EQ.
+expr ::= expr PLUS expr.
+expr ::= expr EQ expr.
+expr ::= NUM.
+`)
+	a := BuildLALR(g)
+	table := BuildTables(a, g)
+	for _, c := range DetectAndResolveConflicts(a, g).Conflicts {
+		got := table.Action[c.StateID][c.Terminal.ID].Kind.String()
+		if got != c.Resolution {
+			t.Errorf("%s: table has %s", c.String(g), got)
+		}
+	}
+}
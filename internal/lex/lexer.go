@@ -53,6 +53,10 @@ func Tokenize(filename string, src []byte) (tokens []Token, err error) {
 			tt = TOKEN_DIR_DESTRUCTOR
 		case scanner.EndIf:
 			tt = TOKEN_DIR_ENDIF
+		case scanner.Expect:
+			tt = TOKEN_DIR_EXPECT
+		case scanner.ExpectRR:
+			tt = TOKEN_DIR_EXPECT_RR
 		case scanner.ExtraArgument:
 			tt = TOKEN_DIR_EXTRA_ARGUMENT
 		case scanner.ExtraContext:
@@ -65,6 +69,8 @@ func Tokenize(filename string, src []byte) (tokens []Token, err error) {
 			tt = TOKEN_DIR_IFNDEF
 		case scanner.Include:
 			tt = TOKEN_DIR_INCLUDE
+		case scanner.Int:
+			tt = TOKEN_INTEGER
 		case scanner.Is:
 			tt = TOKEN_COLONCOLON_EQ
 		case scanner.Left:
@@ -83,6 +89,8 @@ func Tokenize(filename string, src []byte) (tokens []Token, err error) {
 			tt = TOKEN_DIR_RIGHT
 		case scanner.StackSize:
 			tt = TOKEN_DIR_STACK_SIZE
+		case scanner.StackSizeLimit:
+			tt = TOKEN_DIR_STACK_SIZE_LIMIT
 		case scanner.StackOverflow:
 			tt = TOKEN_DIR_STACK_OVERFLOW
 		case scanner.StartSymbol:
@@ -214,6 +214,72 @@ func TestBracesInStringsInCodeBlock(t *testing.T) {
 	}
 }
 
+func TestCommentsInCodeBlock(t *testing.T) {
+	src := []byte("%code {\n// don't { count this\nx := 1 /* or } this */\n}\nexpr ::= IDENT.")
+	tokens, err := Tokenize("test.y", src)
+	if err != nil {
+		t.Fatalf("Tokenize error: %v", err)
+	}
+	expected := []Token{
+		{Type: TOKEN_DIR_CODE},
+		{Type: TOKEN_CODE_BLOCK, Literal: "{\n// don't { count this\nx := 1 /* or } this */\n}"},
+		{Type: TOKEN_NONTERMINAL, Literal: `expr`},
+		{Type: TOKEN_COLONCOLON_EQ},
+		{Type: TOKEN_TERMINAL, Literal: `IDENT`},
+		{Type: TOKEN_DOT},
+		{Type: TOKEN_EOF},
+	}
+	if len(tokens) != len(expected) {
+		for i, tok := range tokens {
+			t.Errorf("%d: got %+v\n", i, tok)
+		}
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(expected))
+	}
+	for i, want := range expected {
+		if tokens[i].Type != want.Type {
+			t.Errorf("token[%d].Type = %v, want %v (literal=%q)",
+				i, tokens[i].Type, want.Type, tokens[i].Literal)
+		}
+		if want.Literal != "" && tokens[i].Literal != want.Literal {
+			t.Errorf("token[%d].Literal = %q, want %q", i, tokens[i].Literal, want.Literal)
+		}
+	}
+}
+
+func TestIntegerLiteral(t *testing.T) {
+	src := []byte(`%stack_size 100 %expect 1 %expect_rr 0 %stack_size_limit 1000`)
+	tokens, err := Tokenize("test.y", src)
+	if err != nil {
+		t.Fatalf("Tokenize error: %v", err)
+	}
+	expected := []Token{
+		{Type: TOKEN_DIR_STACK_SIZE, Literal: `%stack_size`},
+		{Type: TOKEN_INTEGER, Literal: `100`},
+		{Type: TOKEN_DIR_EXPECT, Literal: `%expect`},
+		{Type: TOKEN_INTEGER, Literal: `1`},
+		{Type: TOKEN_DIR_EXPECT_RR, Literal: `%expect_rr`},
+		{Type: TOKEN_INTEGER, Literal: `0`},
+		{Type: TOKEN_DIR_STACK_SIZE_LIMIT, Literal: `%stack_size_limit`},
+		{Type: TOKEN_INTEGER, Literal: `1000`},
+		{Type: TOKEN_EOF},
+	}
+	if len(tokens) != len(expected) {
+		for i, tok := range tokens {
+			t.Errorf("%d: got %+v\n", i, tok)
+		}
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(expected))
+	}
+	for i, want := range expected {
+		if tokens[i].Type != want.Type {
+			t.Errorf("token[%d].Type = %v, want %v (literal=%q)",
+				i, tokens[i].Type, want.Type, tokens[i].Literal)
+		}
+		if want.Literal != "" && tokens[i].Literal != want.Literal {
+			t.Errorf("token[%d].Literal = %q, want %q", i, tokens[i].Literal, want.Literal)
+		}
+	}
+}
+
 func TestEmptyInput(t *testing.T) {
 	src := []byte(``)
 	tokens, err := Tokenize("test.y", src)
@@ -59,23 +59,29 @@ const (
 	TOKEN_DIR_START_SYMBOL // %start_symbol
 	TOKEN_DIR_TOKEN_CLASS
 	TOKEN_DIR_TOKEN_DESTRUCTOR
-	TOKEN_DIR_TOKEN_PREFIX   // %token_prefix
-	TOKEN_DIR_TOKEN_TYPE     // %token_type
-	TOKEN_DIR_TYPE           // %type
-	TOKEN_DIR_FALLBACK       // %fallback
-	TOKEN_DIR_WILDCARD       // %wildcard
-	TOKEN_DIR_DESTRUCTOR     // %destructor
-	TOKEN_DIR_SYNTAX_ERROR   // %syntax_error
-	TOKEN_DIR_PARSE_ACCEPT   // %parse_accept
-	TOKEN_DIR_PARSE_FAILURE  // %parse_failure
-	TOKEN_DIR_STACK_OVERFLOW // %stack_overflow
-	TOKEN_DIR_GENERIC        // unknown %directive
+	TOKEN_DIR_TOKEN_PREFIX     // %token_prefix
+	TOKEN_DIR_TOKEN_TYPE       // %token_type
+	TOKEN_DIR_TYPE             // %type
+	TOKEN_DIR_FALLBACK         // %fallback
+	TOKEN_DIR_WILDCARD         // %wildcard
+	TOKEN_DIR_DESTRUCTOR       // %destructor
+	TOKEN_DIR_SYNTAX_ERROR     // %syntax_error
+	TOKEN_DIR_PARSE_ACCEPT     // %parse_accept
+	TOKEN_DIR_PARSE_FAILURE    // %parse_failure
+	TOKEN_DIR_STACK_OVERFLOW   // %stack_overflow
+	TOKEN_DIR_EXPECT           // %expect
+	TOKEN_DIR_EXPECT_RR        // %expect_rr
+	TOKEN_DIR_STACK_SIZE_LIMIT // %stack_size_limit
+	TOKEN_DIR_GENERIC          // unknown %directive
 
 	// Code blocks
 	TOKEN_CODE_BLOCK // { ... } (Go/C code in actions or directives)
 
 	// Alias
 	TOKEN_STRING // "quoted string" (alias for a token)
+
+	// Literals
+	TOKEN_INTEGER // decimal integer (e.g., %stack_size 100)
 )
 
 // Token is a single lexical unit from a Lemon grammar file.
@@ -21,8 +21,8 @@ func TestTokenTypeString(t *testing.T) {
 		TOKEN_DIR_TOKEN_PREFIX, TOKEN_DIR_FALLBACK, TOKEN_DIR_WILDCARD,
 		TOKEN_DIR_DESTRUCTOR, TOKEN_DIR_SYNTAX_ERROR,
 		TOKEN_DIR_PARSE_ACCEPT, TOKEN_DIR_PARSE_FAILURE, TOKEN_DIR_STACK_OVERFLOW,
-		TOKEN_DIR_GENERIC,
-		TOKEN_CODE_BLOCK, TOKEN_STRING,
+		TOKEN_DIR_EXPECT, TOKEN_DIR_EXPECT_RR, TOKEN_DIR_STACK_SIZE_LIMIT, TOKEN_DIR_GENERIC,
+		TOKEN_CODE_BLOCK, TOKEN_STRING, TOKEN_INTEGER,
 	}
 	seen := map[string]bool{}
 	for _, tt := range types {
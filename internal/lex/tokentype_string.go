@@ -47,14 +47,18 @@ func _() {
 	_ = x[TOKEN_DIR_PARSE_ACCEPT-36]
 	_ = x[TOKEN_DIR_PARSE_FAILURE-37]
 	_ = x[TOKEN_DIR_STACK_OVERFLOW-38]
-	_ = x[TOKEN_DIR_GENERIC-39]
-	_ = x[TOKEN_CODE_BLOCK-40]
-	_ = x[TOKEN_STRING-41]
+	_ = x[TOKEN_DIR_EXPECT-39]
+	_ = x[TOKEN_DIR_EXPECT_RR-40]
+	_ = x[TOKEN_DIR_STACK_SIZE_LIMIT-41]
+	_ = x[TOKEN_DIR_GENERIC-42]
+	_ = x[TOKEN_CODE_BLOCK-43]
+	_ = x[TOKEN_STRING-44]
+	_ = x[TOKEN_INTEGER-45]
 }
 
-const _TokenType_name = "TOKEN_EOFTOKEN_ERRORTOKEN_TERMINALTOKEN_NONTERMINALTOKEN_COLONCOLON_EQTOKEN_DOTTOKEN_PIPETOKEN_LPARENTOKEN_RPARENTOKEN_LBRACKETTOKEN_RBRACKETTOKEN_COMMATOKEN_DIR_CODETOKEN_DIR_DEFAULT_DESTRUCTORTOKEN_DIR_DEFAULT_TYPETOKEN_DIR_ENDIFTOKEN_DIR_EXTRA_ARGUMENTTOKEN_DIR_EXTRA_CONTEXTTOKEN_DIR_INCLUDETOKEN_DIR_IFDEFTOKEN_DIR_IFNDEFTOKEN_DIR_LEFTTOKEN_DIR_NAMETOKEN_DIR_NONASSOCTOKEN_DIR_RIGHTTOKEN_DIR_STACK_SIZETOKEN_DIR_START_SYMBOLTOKEN_DIR_TOKEN_CLASSTOKEN_DIR_TOKEN_DESTRUCTORTOKEN_DIR_TOKEN_PREFIXTOKEN_DIR_TOKEN_TYPETOKEN_DIR_TYPETOKEN_DIR_FALLBACKTOKEN_DIR_WILDCARDTOKEN_DIR_DESTRUCTORTOKEN_DIR_SYNTAX_ERRORTOKEN_DIR_PARSE_ACCEPTTOKEN_DIR_PARSE_FAILURETOKEN_DIR_STACK_OVERFLOWTOKEN_DIR_GENERICTOKEN_CODE_BLOCKTOKEN_STRING"
+const _TokenType_name = "TOKEN_EOFTOKEN_ERRORTOKEN_TERMINALTOKEN_NONTERMINALTOKEN_COLONCOLON_EQTOKEN_DOTTOKEN_PIPETOKEN_LPARENTOKEN_RPARENTOKEN_LBRACKETTOKEN_RBRACKETTOKEN_COMMATOKEN_DIR_CODETOKEN_DIR_DEFAULT_DESTRUCTORTOKEN_DIR_DEFAULT_TYPETOKEN_DIR_ENDIFTOKEN_DIR_EXTRA_ARGUMENTTOKEN_DIR_EXTRA_CONTEXTTOKEN_DIR_INCLUDETOKEN_DIR_IFDEFTOKEN_DIR_IFNDEFTOKEN_DIR_LEFTTOKEN_DIR_NAMETOKEN_DIR_NONASSOCTOKEN_DIR_RIGHTTOKEN_DIR_STACK_SIZETOKEN_DIR_START_SYMBOLTOKEN_DIR_TOKEN_CLASSTOKEN_DIR_TOKEN_DESTRUCTORTOKEN_DIR_TOKEN_PREFIXTOKEN_DIR_TOKEN_TYPETOKEN_DIR_TYPETOKEN_DIR_FALLBACKTOKEN_DIR_WILDCARDTOKEN_DIR_DESTRUCTORTOKEN_DIR_SYNTAX_ERRORTOKEN_DIR_PARSE_ACCEPTTOKEN_DIR_PARSE_FAILURETOKEN_DIR_STACK_OVERFLOWTOKEN_DIR_EXPECTTOKEN_DIR_EXPECT_RRTOKEN_DIR_STACK_SIZE_LIMITTOKEN_DIR_GENERICTOKEN_CODE_BLOCKTOKEN_STRINGTOKEN_INTEGER"
 
-var _TokenType_index = [...]uint16{0, 9, 20, 34, 51, 70, 79, 89, 101, 113, 127, 141, 152, 166, 194, 216, 231, 255, 278, 295, 310, 326, 340, 354, 372, 387, 407, 429, 450, 476, 498, 518, 532, 550, 568, 588, 610, 632, 655, 679, 696, 712, 724}
+var _TokenType_index = [...]uint16{0, 9, 20, 34, 51, 70, 79, 89, 101, 113, 127, 141, 152, 166, 194, 216, 231, 255, 278, 295, 310, 326, 340, 354, 372, 387, 407, 429, 450, 476, 498, 518, 532, 550, 568, 588, 610, 632, 655, 679, 695, 714, 740, 757, 773, 785, 798}
 
 func (i TokenType) String() string {
 	idx := int(i) - 0
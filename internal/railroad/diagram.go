@@ -0,0 +1,211 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package railroad draws syntax (railroad) diagrams of a grammar as SVG.
+//
+// Each nonterminal becomes one diagram: its rules are alternatives of a
+// choice, and a directly recursive list such as
+//
+//	list ::= list COMMA item.
+//	list ::= item.
+//
+// is drawn as a loop over item with COMMA on the way back, as a reader
+// would write it by hand.
+package railroad
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// node is an element of a diagram.
+type node interface {
+	String() string
+}
+
+// terminal is a token, drawn as a rounded box.
+type terminal struct{ text string }
+
+// nonterminal is a reference to another diagram, drawn as a square box.
+type nonterminal struct{ name string }
+
+// sequence is a series of nodes.
+type sequence struct{ items []node }
+
+// choice is a set of alternatives, the first on the main line.
+type choice struct{ alts []node }
+
+// loop is body repeated one or more times, with sep between repetitions.
+type loop struct{ body, sep node }
+
+// skip is an empty path.
+type skip struct{}
+
+func (n terminal) String() string    { return n.text }
+func (n nonterminal) String() string { return "<" + n.name + ">" }
+func (n skip) String() string        { return "ε" }
+
+func (n sequence) String() string {
+	list := make([]string, len(n.items))
+	for i, item := range n.items {
+		list[i] = item.String()
+	}
+	return "(" + strings.Join(list, " ") + ")"
+}
+
+func (n choice) String() string {
+	list := make([]string, len(n.alts))
+	for i, alt := range n.alts {
+		list[i] = alt.String()
+	}
+	return "(" + strings.Join(list, " | ") + ")"
+}
+
+func (n loop) String() string {
+	if _, ok := n.sep.(skip); ok {
+		return "{" + n.body.String() + "}"
+	}
+	return "{" + n.body.String() + " / " + n.sep.String() + "}"
+}
+
+// Diagram is the railroad diagram of one nonterminal.
+type Diagram struct {
+	Name string
+	root node
+}
+
+// String describes the diagram in a compact text form: (a b) is a
+// sequence, (a | b) a choice, {a / s} one or more a separated by s, {a}
+// one or more a, <x> a nonterminal and ε the empty path.
+func (d *Diagram) String() string {
+	return d.Name + " = " + d.root.String()
+}
+
+// Build returns a diagram for every nonterminal of a finalized grammar
+// except the augmented start symbol, in symbol order.
+func Build(g *grammar.Grammar) []*Diagram {
+	var list []*Diagram
+	for _, nt := range g.Symbols.Nonterminals() {
+		if nt == g.Accept {
+			continue
+		}
+		list = append(list, &Diagram{Name: nt.Name, root: build(nt, g.RulesFor(nt))})
+	}
+	return list
+}
+
+// build collapses direct left or right recursion into a loop and draws
+// anything else as a choice of the rules.
+func build(nt *grammar.Symbol, rules []*grammar.Rule) node {
+	var left, right, base [][]*grammar.Symbol
+	for _, r := range rules {
+		switch n := len(r.RHS); {
+		case n > 1 && r.RHS[0] == nt:
+			left = append(left, r.RHS[1:])
+		case n > 1 && r.RHS[n-1] == nt:
+			right = append(right, r.RHS[:n-1])
+		case n == 1 && r.RHS[0] == nt:
+			// A ::= A adds nothing to the language.
+		default:
+			base = append(base, r.RHS)
+		}
+	}
+	switch {
+	case len(base) == 0 || len(left) > 0 && len(right) > 0:
+	case len(left) > 0:
+		// A ::= A t | b is b t*.
+		if len(left) == 1 && len(base) == 1 && hasSuffix(left[0], base[0]) && len(base[0]) > 0 {
+			// A ::= A s b | b is b (s b)*.
+			return loop{body: seq(base[0]), sep: seq(left[0][:len(left[0])-len(base[0])])}
+		}
+		return concat(alternatives(base), zeroOrMore(alternatives(left)))
+	case len(right) > 0:
+		// A ::= h A | b is h* b.
+		if len(right) == 1 && len(base) == 1 && hasPrefix(right[0], base[0]) && len(base[0]) > 0 {
+			// A ::= b s A | b is (b s)* b.
+			return loop{body: seq(base[0]), sep: seq(right[0][len(base[0]):])}
+		}
+		return concat(zeroOrMore(alternatives(right)), alternatives(base))
+	}
+	var all [][]*grammar.Symbol
+	for _, r := range rules {
+		all = append(all, r.RHS)
+	}
+	return alternatives(all)
+}
+
+// zeroOrMore is an optional loop.
+func zeroOrMore(n node) node {
+	return choice{alts: []node{skip{}, loop{body: n, sep: skip{}}}}
+}
+
+// concat joins two nodes into a sequence, dropping empty paths.
+func concat(a, b node) node {
+	var items []node
+	for _, n := range []node{a, b} {
+		if s, ok := n.(sequence); ok {
+			items = append(items, s.items...)
+		} else if _, ok := n.(skip); !ok {
+			items = append(items, n)
+		}
+	}
+	return simplify(items)
+}
+
+// alternatives is a choice between symbol strings. An empty string is the
+// empty path, drawn first so that the other alternatives bypass it.
+func alternatives(list [][]*grammar.Symbol) node {
+	var alts []node
+	for _, syms := range list {
+		n := seq(syms)
+		if _, ok := n.(skip); ok {
+			if !slices.ContainsFunc(alts, isSkip) {
+				alts = slices.Insert(alts, 0, n)
+			}
+			continue
+		}
+		alts = append(alts, n)
+	}
+	if len(alts) == 1 {
+		return alts[0]
+	}
+	return choice{alts: alts}
+}
+
+func isSkip(n node) bool {
+	_, ok := n.(skip)
+	return ok
+}
+
+// seq is a sequence of grammar symbols.
+func seq(syms []*grammar.Symbol) node {
+	items := make([]node, len(syms))
+	for i, sym := range syms {
+		if sym.IsTerminal() {
+			items[i] = terminal{text: sym.DisplayName()}
+		} else {
+			items[i] = nonterminal{name: sym.Name}
+		}
+	}
+	return simplify(items)
+}
+
+// simplify drops the sequence around zero or one item.
+func simplify(items []node) node {
+	switch len(items) {
+	case 0:
+		return skip{}
+	case 1:
+		return items[0]
+	}
+	return sequence{items: items}
+}
+
+func hasPrefix(s, prefix []*grammar.Symbol) bool {
+	return len(s) >= len(prefix) && slices.Equal(s[:len(prefix)], prefix)
+}
+
+func hasSuffix(s, suffix []*grammar.Symbol) bool {
+	return len(s) >= len(suffix) && slices.Equal(s[len(s)-len(suffix):], suffix)
+}
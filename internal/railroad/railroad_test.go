@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package railroad
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func TestBuild(t *testing.T) {
+	g := mustGrammar(t, `
+%token SEMI ";" COMMA ",".
+program ::= stmts.
+stmts ::= stmts stmt.
+stmts ::= .
+stmt ::= PRINT args SEMI.
+stmt ::= ID ASSIGN expr SEMI.
+args ::= args COMMA expr.
+args ::= expr.
+ids ::= ID COMMA ids.
+ids ::= ID.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= LPAREN expr RPAREN.
+expr ::= NUM.
+opt ::= ids.
+opt ::= .
+`)
+	var got []string
+	for _, d := range Build(g) {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"program = <stmts>",
+		"stmts = (ε | {<stmt>})",
+		"stmt = ((PRINT <args> ;) | (ID ASSIGN <expr> ;))",
+		"args = {<expr> / ,}",
+		"expr = (((LPAREN <expr> RPAREN) | NUM) (ε | {((PLUS <expr>) | (TIMES <expr>))}))",
+		"ids = {ID / ,}",
+		"opt = (ε | <ids>)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diagrams:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMixedRecursionIsNotALoop(t *testing.T) {
+	g := mustGrammar(t, `
+s ::= s A.
+s ::= B s.
+s ::= C.
+`)
+	if got, want := Build(g)[0].String(), "s = ((<s> A) | (B <s>) | C)"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// wellFormed reports whether src parses as XML.
+func wellFormed(t *testing.T, src string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(src))
+	d.Strict = true
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("malformed output: %v\n%s", err, src)
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	g := mustGrammar(t, `
+%token LT "<".
+list ::= list LT item.
+list ::= item.
+item ::= NUM.
+item ::= .
+`)
+	var sb strings.Builder
+	if err := Build(g)[0].WriteSVG(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	wellFormed(t, out)
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		`<text class="title" x="20" y="34">list</text>`,
+		`<a href="item.svg">`,
+		`>&lt;</text>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	g := mustGrammar(t, "a ::= b.\nb ::= X.\n")
+	var sb strings.Builder
+	if err := WriteIndex(&sb, "a & b", Build(g)); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"<title>a &amp; b</title>",
+		`<li><a href="#a">a</a></li>`,
+		`<h2 id="b"><a href="b.svg">b</a></h2>`,
+		`<img src="b.svg" alt="b">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("index is missing %q:\n%s", want, out)
+		}
+	}
+}
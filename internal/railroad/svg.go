@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package railroad
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"unicode/utf8"
+)
+
+// Layout constants, in pixels.
+const (
+	charWidth = 8.5 // advance of one character in the 14px monospace font
+	boxHeight = 24  // height of a terminal or nonterminal box
+	boxPad    = 10  // space between a box's text and its sides
+	gap       = 10  // horizontal space between items of a sequence
+	rowGap    = 10  // vertical space between alternatives
+	curve     = 10  // radius of the turns into and out of branches
+	margin    = 20  // space around the diagram
+	titleSize = 24  // height of the title line
+)
+
+// size is the extent of a node around its baseline: it is entered at the
+// left end of the baseline and left at the right end.
+type size struct {
+	w, up, down float64
+}
+
+func measure(n node) size {
+	switch n := n.(type) {
+	case terminal:
+		return box(n.text)
+	case nonterminal:
+		return box(n.name)
+	case sequence:
+		var s size
+		for i, item := range n.items {
+			m := measure(item)
+			if i > 0 {
+				s.w += gap
+			}
+			s.w += m.w
+			s.up, s.down = max(s.up, m.up), max(s.down, m.down)
+		}
+		return s
+	case choice:
+		first := measure(n.alts[0])
+		s := size{w: first.w, up: first.up}
+		offsets := altOffsets(n)
+		for i, alt := range n.alts {
+			m := measure(alt)
+			s.w = max(s.w, m.w)
+			s.down = max(s.down, offsets[i]+m.down)
+		}
+		s.w += 4 * curve
+		return s
+	case loop:
+		body, sep := measure(n.body), measure(n.sep)
+		return size{
+			w:    max(body.w, sep.w) + 4*curve,
+			up:   body.up,
+			down: loopOffset(n) + sep.down,
+		}
+	}
+	return size{}
+}
+
+// box is the size of a box around text.
+func box(text string) size {
+	return size{w: textWidth(text) + 2*boxPad, up: boxHeight / 2, down: boxHeight / 2}
+}
+
+func textWidth(text string) float64 {
+	return float64(utf8.RuneCountInString(text)) * charWidth
+}
+
+// altOffsets returns how far below the choice's baseline each alternative's
+// baseline lies.
+func altOffsets(n choice) []float64 {
+	offsets := make([]float64, len(n.alts))
+	prev := measure(n.alts[0])
+	for i := 1; i < len(n.alts); i++ {
+		m := measure(n.alts[i])
+		offsets[i] = max(offsets[i-1]+prev.down+rowGap+m.up, offsets[i-1]+2*curve)
+		prev = m
+	}
+	return offsets
+}
+
+// loopOffset returns how far below the loop's baseline the return path
+// lies.
+func loopOffset(n loop) float64 {
+	body, sep := measure(n.body), measure(n.sep)
+	return max(body.down+rowGap+sep.up, 2*curve)
+}
+
+// renderer writes SVG elements.
+type renderer struct {
+	w *bufio.Writer
+}
+
+func (r *renderer) line(x1, y1, x2, y2 float64) {
+	if x1 != x2 || y1 != y2 {
+		fmt.Fprintf(r.w, "<path d=\"M%g %gL%g %g\"/>\n", x1, y1, x2, y2)
+	}
+}
+
+// turn draws a path from (x1, y1) to (x2, y2) that leaves and arrives
+// horizontally, turning through a vertical line halfway across.
+func (r *renderer) turn(x1, y1, x2, y2 float64) {
+	xm, dy := (x1+x2)/2, float64(curve)
+	if y2 < y1 {
+		dy = -dy
+	}
+	fmt.Fprintf(r.w, "<path d=\"M%g %gQ%g %g %g %gL%g %gQ%g %g %g %g\"/>\n",
+		x1, y1, xm, y1, xm, y1+dy, xm, y2-dy, xm, y2, x2, y2)
+}
+
+// uturn draws a path from (x, y1) back to (x, y2) that bulges out by one
+// curve radius, to the right if dx is 1 and to the left if it is -1.
+func (r *renderer) uturn(x, y1, y2, dx float64) {
+	xv, dy := x+dx*curve, float64(curve)
+	if y2 < y1 {
+		dy = -dy
+	}
+	fmt.Fprintf(r.w, "<path d=\"M%g %gQ%g %g %g %gL%g %gQ%g %g %g %g\"/>\n",
+		x, y1, xv, y1, xv, y1+dy, xv, y2-dy, xv, y2, x, y2)
+}
+
+// draw draws n with its entry at (x, y).
+func (r *renderer) draw(n node, x, y float64) {
+	switch n := n.(type) {
+	case terminal:
+		r.box(n.text, x, y, boxHeight/2, "")
+	case nonterminal:
+		r.box(n.name, x, y, 0, n.name+".svg")
+	case sequence:
+		for i, item := range n.items {
+			if i > 0 {
+				r.line(x, y, x+gap, y)
+				x += gap
+			}
+			r.draw(item, x, y)
+			x += measure(item).w
+		}
+	case choice:
+		s := measure(n)
+		inner := s.w - 4*curve
+		for i, alt := range n.alts {
+			m := measure(alt)
+			ay := y + altOffsets(n)[i]
+			if i == 0 {
+				r.line(x, y, x+2*curve, y)
+				r.line(x+s.w-2*curve, y, x+s.w, y)
+			} else {
+				r.turn(x, y, x+2*curve, ay)
+				r.turn(x+s.w-2*curve, ay, x+s.w, y)
+			}
+			r.draw(alt, x+2*curve, ay)
+			r.line(x+2*curve+m.w, ay, x+2*curve+inner, ay)
+		}
+	case loop:
+		s := measure(n)
+		inner := s.w - 4*curve
+		body, sep := measure(n.body), measure(n.sep)
+		ly := y + loopOffset(n)
+		r.line(x, y, x+2*curve, y)
+		r.draw(n.body, x+2*curve, y)
+		r.line(x+2*curve+body.w, y, x+s.w, y)
+		// The return path runs right to left under the body.
+		r.uturn(x+s.w-2*curve, y, ly, 1)
+		r.uturn(x+2*curve, ly, y, -1)
+		sx := x + 2*curve + (inner-sep.w)/2
+		r.line(x+2*curve, ly, sx, ly)
+		r.draw(n.sep, sx, ly)
+		r.line(sx+sep.w, ly, x+s.w-2*curve, ly)
+	}
+}
+
+// box draws a box around text with corner radius rx. If href is set the
+// box links to it.
+func (r *renderer) box(text string, x, y, rx float64, href string) {
+	m := box(text)
+	if href != "" {
+		fmt.Fprintf(r.w, "<a href=\"%s\">\n", html.EscapeString(href))
+	}
+	fmt.Fprintf(r.w, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%d\" rx=\"%g\"/>\n", x, y-boxHeight/2, m.w, boxHeight, rx)
+	fmt.Fprintf(r.w, "<text x=\"%g\" y=\"%g\">%s</text>\n", x+m.w/2, y+5, html.EscapeString(text))
+	if href != "" {
+		fmt.Fprintln(r.w, "</a>")
+	}
+}
+
+const style = `<style>
+path { fill: none; stroke: #333; stroke-width: 2; }
+rect { fill: #eef; stroke: #333; stroke-width: 2; }
+text { font: 14px monospace; text-anchor: middle; }
+text.title { font-weight: bold; text-anchor: start; }
+</style>
+`
+
+// WriteSVG writes the diagram as a standalone SVG document. Nonterminals
+// link to the diagram files "<name>.svg" beside it.
+func (d *Diagram) WriteSVG(w io.Writer) error {
+	s := measure(d.root)
+	// The diagram starts and ends with a short line and a bar.
+	width := s.w + 4*gap + 2*margin
+	y := margin + titleSize + max(s.up, boxHeight/2)
+	height := y + max(s.down, boxHeight/2) + margin
+
+	r := &renderer{w: bufio.NewWriter(w)}
+	fmt.Fprintf(r.w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\">\n",
+		width, height, width, height)
+	r.w.WriteString(style)
+	fmt.Fprintf(r.w, "<text class=\"title\" x=\"%d\" y=\"%d\">%s</text>\n", margin, margin+14, html.EscapeString(d.Name))
+	x := float64(margin)
+	fmt.Fprintf(r.w, "<path d=\"M%g %gv%dM%g %gv%d\"/>\n", x, y-gap, 2*gap, x+gap/2, y-gap, 2*gap)
+	r.line(x, y, x+2*gap, y)
+	r.draw(d.root, x+2*gap, y)
+	x += 2*gap + s.w
+	r.line(x, y, x+2*gap, y)
+	x += 2 * gap
+	fmt.Fprintf(r.w, "<path d=\"M%g %gv%dM%g %gv%d\"/>\n", x-gap/2, y-gap, 2*gap, x, y-gap, 2*gap)
+	fmt.Fprintln(r.w, "</svg>")
+	return r.w.Flush()
+}
+
+// WriteIndex writes an HTML page that shows every diagram, each from its
+// file "<name>.svg", with a table of contents.
+func WriteIndex(w io.Writer, title string, diagrams []*Diagram) error {
+	bw := bufio.NewWriter(w)
+	t := html.EscapeString(title)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<ul>\n", t, t)
+	for _, d := range diagrams {
+		name := html.EscapeString(d.Name)
+		fmt.Fprintf(bw, "<li><a href=\"#%s\">%s</a></li>\n", name, name)
+	}
+	fmt.Fprintln(bw, "</ul>")
+	for _, d := range diagrams {
+		name := html.EscapeString(d.Name)
+		fmt.Fprintf(bw, "<h2 id=\"%s\"><a href=\"%s.svg\">%s</a></h2>\n<img src=\"%s.svg\" alt=\"%s\">\n", name, name, name, name, name)
+	}
+	fmt.Fprintln(bw, "</body>\n</html>")
+	return bw.Flush()
+}
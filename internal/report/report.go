@@ -0,0 +1,232 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package report writes the human-readable description of a parser that
+// Lemon calls the ".out" file: every state with its items and the action
+// taken on each lookahead, followed by the conflicts and the symbols.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/counterexample"
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// Options selects what the report shows.
+type Options struct {
+	Basis           bool // list only the basis (kernel) items of each state
+	Precedence      bool // show conflicts resolved by precedence and the precedence levels
+	Counterexamples bool // follow each unresolved conflict with a counterexample
+}
+
+// Write writes the report for an automaton with lookaheads.
+func Write(w io.Writer, a *lalr.Automaton, opts Options) error {
+	g := a.Grammar
+	conflicts := lalr.DetectAndResolveConflicts(a, g)
+	table := lalr.BuildTables(a, g)
+	byState := make([][]lalr.Conflict, len(a.States))
+	for _, c := range conflicts.Conflicts {
+		byState[c.StateID] = append(byState[c.StateID], c)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, s := range a.States {
+		writeState(bw, a, s, table, byState[s.ID], opts)
+	}
+	writeConflicts(bw, a, conflicts, opts)
+	if opts.Precedence {
+		writePrecedence(bw, g)
+	}
+	writeSymbols(bw, g)
+	return bw.Flush()
+}
+
+// writeState lists the items of a state, then its actions in symbol order.
+// A conflict adds the action that lost after the one that won.
+func writeState(w io.Writer, a *lalr.Automaton, s *lalr.State, table *lalr.ParseTable, conflicts []lalr.Conflict, opts Options) {
+	g := a.Grammar
+	fmt.Fprintf(w, "State %d:\n", s.ID)
+	items := s.Items.Items
+	if opts.Basis {
+		items = s.Kernel(g)
+	}
+	for _, item := range items {
+		fmt.Fprintf(w, "%10s%s\n", "", item.String(g))
+	}
+	fmt.Fprintln(w)
+
+	for _, t := range g.Symbols.Terminals() {
+		if act := table.Action[s.ID][t.ID]; act.Kind != lalr.ActionError {
+			fmt.Fprintf(w, "%30s %s\n", t.Name, actionText(g, act))
+		} else if opts.Precedence && hasConflictOn(conflicts, t) {
+			fmt.Fprintf(w, "%30s %s\n", t.Name, actionText(g, act))
+		}
+		for _, c := range conflicts {
+			if c.Terminal != t || (c.Resolved && !opts.Precedence) {
+				continue
+			}
+			note := "** Parsing conflict **"
+			if c.Resolved {
+				note = "-- dropped by precedence"
+			}
+			for _, act := range losers(a, c) {
+				fmt.Fprintf(w, "%30s %s %s\n", t.Name, actionText(g, act), note)
+			}
+		}
+	}
+	for _, nt := range g.Symbols.Nonterminals() {
+		if to := table.GotoState(s.ID, nt); to >= 0 {
+			fmt.Fprintf(w, "%30s goto   %d\n", nt.Name, to)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// hasConflictOn reports whether any conflict in the list is on terminal t.
+func hasConflictOn(conflicts []lalr.Conflict, t *grammar.Symbol) bool {
+	for _, c := range conflicts {
+		if c.Terminal == t {
+			return true
+		}
+	}
+	return false
+}
+
+// losers returns the actions a conflict did not choose.
+func losers(a *lalr.Automaton, c lalr.Conflict) []lalr.Action {
+	g := a.Grammar
+	reduce := reduceAction(g, c.ReduceItem.RuleIndex)
+	if c.Kind == lalr.ReduceReduce {
+		return []lalr.Action{reduceAction(g, c.ReduceItem2.RuleIndex)}
+	}
+	shift := lalr.Action{Kind: lalr.ActionShift, State: a.Next(c.StateID, c.Terminal)}
+	switch c.Resolution {
+	case "shift":
+		return []lalr.Action{reduce}
+	case "reduce":
+		return []lalr.Action{shift}
+	}
+	return []lalr.Action{shift, reduce}
+}
+
+func reduceAction(g *grammar.Grammar, ruleIndex int) lalr.Action {
+	if g.Rules[ruleIndex] == g.AcceptRule {
+		return lalr.Action{Kind: lalr.ActionAccept}
+	}
+	return lalr.Action{Kind: lalr.ActionReduce, RuleIndex: ruleIndex}
+}
+
+// actionText describes an action; a reduce names its rule.
+func actionText(g *grammar.Grammar, act lalr.Action) string {
+	switch act.Kind {
+	case lalr.ActionShift:
+		return fmt.Sprintf("shift  %d", act.State)
+	case lalr.ActionReduce:
+		return fmt.Sprintf("reduce %-4d %s", act.RuleIndex, g.Rules[act.RuleIndex])
+	}
+	return act.Kind.String()
+}
+
+// writeConflicts lists the unresolved conflicts, and under -p the resolved
+// ones too.
+func writeConflicts(w io.Writer, a *lalr.Automaton, conflicts *lalr.ConflictReport, opts Options) {
+	g := a.Grammar
+	if conflicts.Unresolved > 0 {
+		fmt.Fprintf(w, "Conflicts: %d\n", conflicts.Unresolved)
+		for _, c := range conflicts.Conflicts {
+			if c.Resolved {
+				continue
+			}
+			fmt.Fprintf(w, "  %s\n", c.String(g))
+			if !opts.Counterexamples {
+				continue
+			}
+			if ex, err := counterexample.Find(a, c); err == nil {
+				ex.Write(&indenter{w: w, prefix: "    "}, g)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	if opts.Precedence && conflicts.Resolved > 0 {
+		fmt.Fprintf(w, "Resolved by precedence: %d\n", conflicts.Resolved)
+		for _, c := range conflicts.Conflicts {
+			if c.Resolved {
+				fmt.Fprintf(w, "  %s\n", c.String(g))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writePrecedence lists the precedence levels from lowest to highest, if
+// the grammar declares any.
+func writePrecedence(w io.Writer, g *grammar.Grammar) {
+	levels := map[int][]*grammar.Symbol{}
+	top := 0
+	for _, t := range g.Symbols.Terminals() {
+		if p := t.Precedence; !p.IsZero() {
+			levels[p.Level] = append(levels[p.Level], t)
+			top = max(top, p.Level)
+		}
+	}
+	if top == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Precedence:")
+	for level := 1; level <= top; level++ {
+		syms := levels[level]
+		if len(syms) == 0 {
+			continue
+		}
+		names := make([]string, len(syms))
+		for i, t := range syms {
+			names[i] = t.Name
+		}
+		fmt.Fprintf(w, "%6d %-9s %s\n", level, syms[0].Precedence.Assoc, strings.Join(names, " "))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeSymbols lists every symbol by ID.
+func writeSymbols(w io.Writer, g *grammar.Grammar) {
+	fmt.Fprintln(w, "Symbols:")
+	for _, sym := range g.Symbols.All() {
+		fmt.Fprintf(w, "%6d: %s", sym.ID, sym.Name)
+		if sym.Alias != "" {
+			fmt.Fprintf(w, " %q", sym.Alias)
+		}
+		if !sym.Precedence.IsZero() {
+			fmt.Fprintf(w, " (precedence=%d)", sym.Precedence.Level)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// indenter prefixes every line written through it.
+type indenter struct {
+	w      io.Writer
+	prefix string
+	mid    bool // true if the last write did not end a line
+}
+
+func (in *indenter) Write(p []byte) (int, error) {
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if line == "" {
+			continue
+		}
+		if !in.mid {
+			if _, err := io.WriteString(in.w, in.prefix); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := io.WriteString(in.w, line); err != nil {
+			return 0, err
+		}
+		in.mid = !strings.HasSuffix(line, "\n")
+	}
+	return len(p), nil
+}
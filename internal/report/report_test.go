@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func render(t *testing.T, src string, opts Options) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := Write(&sb, lalr.BuildLALR(mustGrammar(t, src)), opts); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+const danglingElse = `
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= OTHER.
+expr ::= ID.
+`
+
+const precedence = `
+%left PLUS.
+%left TIMES.
+expr ::= expr PLUS expr.
+expr ::= expr TIMES expr.
+expr ::= NUM.
+`
+
+func TestReportStates(t *testing.T) {
+	out := render(t, danglingElse, Options{})
+	for _, want := range []string{
+		"State 0:\n          stmt ::= * IF expr THEN stmt.\n",
+		"State 7:\n          stmt ::= IF expr THEN stmt *.\n          stmt ::= IF expr THEN stmt * ELSE stmt.\n",
+		"                          ELSE shift  8\n" +
+			"                          ELSE reduce 0    stmt ::= IF expr THEN stmt. ** Parsing conflict **\n",
+		"                          stmt goto   3\n",
+		"                             $ accept\n",
+		"Conflicts: 1\n  state 7: shift/reduce conflict on ELSE",
+		"Symbols:\n     0: $\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Example:") || strings.Contains(out, "Precedence:") {
+		t.Errorf("report has a counterexample or precedence section without asking:\n%s", out)
+	}
+}
+
+func TestReportBasis(t *testing.T) {
+	out := render(t, danglingElse, Options{Basis: true})
+	if want := "State 0:\n          $accept ::= * stmt.\n\n"; !strings.Contains(out, want) {
+		t.Errorf("basis report is missing %q:\n%s", want, out)
+	}
+}
+
+func TestReportCounterexamples(t *testing.T) {
+	out := render(t, danglingElse, Options{Counterexamples: true})
+	if want := "    Example: IF expr THEN IF expr THEN stmt • ELSE stmt\n"; !strings.Contains(out, want) {
+		t.Errorf("report is missing %q:\n%s", want, out)
+	}
+}
+
+func TestReportPrecedence(t *testing.T) {
+	out := render(t, precedence, Options{})
+	if strings.Contains(out, "dropped by precedence") || strings.Contains(out, "Conflicts:") {
+		t.Errorf("resolved conflicts shown without -p:\n%s", out)
+	}
+	out = render(t, precedence, Options{Precedence: true})
+	for _, want := range []string{
+		"-- dropped by precedence\n",
+		"Resolved by precedence: 4\n",
+		"Precedence:\n     1 left      PLUS\n     2 left      TIMES\n",
+		"PLUS (precedence=1)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -66,6 +66,8 @@ const (
 	Destructor
 	Directive
 	EndIf
+	Expect
+	ExpectRR
 	ExtraArgument
 	ExtraContext
 	Fallback
@@ -83,6 +85,7 @@ const (
 	Right
 	StackOverflow
 	StackSize
+	StackSizeLimit
 	StartSymbol
 	SyntaxError
 	Terminal
@@ -110,6 +113,8 @@ var tokenString = map[rune]string{
 	Destructor:        "Destructor",
 	Directive:         "Directive",
 	EndIf:             "EndIf",
+	Expect:            "Expect",
+	ExpectRR:          "ExpectRR",
 	ExtraArgument:     "ExtraArgument",
 	ExtraContext:      "ExtraContext",
 	Fallback:          "Fallback",
@@ -127,6 +132,7 @@ var tokenString = map[rune]string{
 	Right:             "Right",
 	StackOverflow:     "StackOverflow",
 	StackSize:         "StackSize",
+	StackSizeLimit:    "StackSizeLimit",
 	StartSymbol:       "StartSymbol",
 	SyntaxError:       "SyntaxError",
 	Terminal:          "Terminal",
@@ -343,6 +349,30 @@ func (s *Scanner) scanAction() rune {
 				}
 				ch = s.next()
 			}
+		case '/':
+			// Comments may hold unbalanced braces and quotes.
+			switch ch = s.next(); ch {
+			case '/':
+				for ch != '\n' && ch != EOF {
+					ch = s.next()
+				}
+			case '*':
+				ch = s.next()
+				for {
+					if ch == EOF {
+						s.error("unterminated comment in action")
+						return ch
+					} else if ch == '*' {
+						if ch = s.next(); ch == '/' {
+							break
+						}
+						continue
+					}
+					ch = s.next()
+				}
+			default:
+				continue
+			}
 		}
 		ch = s.next()
 	}
@@ -368,6 +398,14 @@ func (s *Scanner) scanIdentifier() rune {
 	return ch
 }
 
+func (s *Scanner) scanInt() rune {
+	ch := s.next()
+	for '0' <= ch && ch <= '9' {
+		ch = s.next()
+	}
+	return ch
+}
+
 func (s *Scanner) scanString(quote rune) {
 	ch := s.next() // read character after quote
 	for ch != quote {
@@ -452,6 +490,9 @@ redo:
 		} else {
 			ch = s.next()
 		}
+	case '0' <= ch && ch <= '9':
+		tok = Int
+		ch = s.scanInt()
 	default:
 		switch ch {
 		case EOF:
@@ -504,6 +545,10 @@ redo:
 				tok = Destructor
 			case "%endif":
 				tok = EndIf
+			case "%expect":
+				tok = Expect
+			case "%expect_rr":
+				tok = ExpectRR
 			case "%extra_argument":
 				tok = ExtraArgument
 			case "%extra_context":
@@ -532,6 +577,8 @@ redo:
 				tok = StackOverflow
 			case "%stack_size":
 				tok = StackSize
+			case "%stack_size_limit":
+				tok = StackSizeLimit
 			case "%start_symbol":
 				tok = StartSymbol
 			case "%syntax_error":
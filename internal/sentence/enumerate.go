@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package sentence
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// language maps a sentence key to the sentence, as symbol IDs.
+type language map[string][]int
+
+// Enumerate returns every distinct sentence of at most maxLen terminals that
+// the start symbol derives, ordered by length and then by symbol ID.
+//
+// It computes, for every nonterminal and every length up to maxLen, the set
+// of strings the nonterminal derives, iterating to a fixed point. Sets only
+// grow and are bounded, so the iteration terminates even for grammars with
+// ε-cycles or left recursion. The number of sentences grows exponentially
+// with maxLen; keep it small. The error pseudo-token derives no string, so
+// no sentence contains it.
+func Enumerate(g *grammar.Grammar, maxLen int) ([][]*grammar.Symbol, error) {
+	if g.Start == nil {
+		return nil, errors.New("sentence: grammar has not been finalized")
+	} else if maxLen < 0 {
+		return nil, errors.New("sentence: maximum length must not be negative")
+	}
+
+	// lang[id][n] holds the strings of length n derived by symbol id.
+	lang := make([][]language, g.Symbols.NumSymbols())
+	for _, s := range g.Symbols.All() {
+		lang[s.ID] = make([]language, maxLen+1)
+		for n := range lang[s.ID] {
+			lang[s.ID][n] = language{}
+		}
+		if s.Kind == grammar.SymbolTerminal && s.Name != grammar.ErrorName && maxLen > 0 {
+			lang[s.ID][1][key([]int{s.ID})] = []int{s.ID}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			// cur[n] holds the strings of length n derived by the RHS prefix.
+			cur := make([]language, maxLen+1)
+			for n := range cur {
+				cur[n] = language{}
+			}
+			cur[0][""] = nil
+			for _, s := range r.RHS {
+				next := make([]language, maxLen+1)
+				for n := range next {
+					next[n] = language{}
+				}
+				for n, prefixes := range cur {
+					for m := 0; n+m <= maxLen; m++ {
+						for _, suffix := range lang[s.ID][m] {
+							for _, prefix := range prefixes {
+								seq := append(slices.Clip(prefix), suffix...)
+								next[n+m][key(seq)] = seq
+							}
+						}
+					}
+				}
+				cur = next
+			}
+			for n, seqs := range cur {
+				for k, seq := range seqs {
+					if _, ok := lang[r.LHS.ID][n][k]; !ok {
+						lang[r.LHS.ID][n][k] = seq
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	var sentences [][]*grammar.Symbol
+	for _, strs := range lang[g.Start.ID] {
+		var ids [][]int
+		for _, seq := range strs {
+			ids = append(ids, seq)
+		}
+		slices.SortFunc(ids, slices.Compare[[]int])
+		for _, seq := range ids {
+			sentence := make([]*grammar.Symbol, len(seq))
+			for i, id := range seq {
+				sentence[i] = g.Symbols.Symbol(id)
+			}
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences, nil
+}
+
+func key(seq []int) string {
+	var sb strings.Builder
+	for i, id := range seq {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(id))
+	}
+	return sb.String()
+}
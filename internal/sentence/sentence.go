@@ -0,0 +1,272 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package sentence generates sentences (strings of terminals) from a grammar.
+// The sentences are meant for fuzzing: they are streams of terminal names
+// that a hand-written lexer's tests can replay against a generated parser.
+package sentence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+)
+
+// DefaultMaxDepth is the derivation depth used when Options.MaxDepth is zero.
+const DefaultMaxDepth = 8
+
+// Options control random sentence generation.
+type Options struct {
+	// MaxDepth is the derivation depth at which the generator stops picking
+	// rules at random and finishes every open nonterminal with one of its
+	// shallowest rules. Zero means DefaultMaxDepth.
+	MaxDepth int
+
+	// Weights biases rule selection. Weights[i] is the relative weight of the
+	// rule with index i; rules that are not listed weigh 1. A rule with weight
+	// 0 is only used when it is needed to finish a derivation.
+	Weights map[int]float64
+}
+
+// Generator produces random sentences from a finalized grammar.
+type Generator struct {
+	g    *grammar.Grammar
+	rng  *rand.Rand
+	opts Options
+
+	// height is the minimum derivation tree height of each symbol;
+	// terminals have height 0, and unproductive nonterminals and the error
+	// pseudo-token, which no lexer returns, math.MaxInt.
+	height []int
+	// rules holds the productive rules for each nonterminal, by symbol ID.
+	rules [][]*grammar.Rule
+	// via records how each nonterminal reachable from the start symbol
+	// is first reached, for Covering.
+	via map[*grammar.Symbol]step
+}
+
+// New returns a generator for g, which must have been finalized.
+func New(g *grammar.Grammar, rng *rand.Rand, opts Options) (*Generator, error) {
+	if g.Start == nil {
+		return nil, errors.New("sentence: grammar has not been finalized")
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	for idx, w := range opts.Weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("sentence: rule %d: weight %v must be a finite, non-negative number", idx, w)
+		}
+	}
+	gen := &Generator{g: g, rng: rng, opts: opts, height: heights(g)}
+	if gen.height[g.Start.ID] == math.MaxInt {
+		return nil, fmt.Errorf("sentence: start symbol %s derives no terminal string", g.Start.Name)
+	}
+	gen.rules = make([][]*grammar.Rule, g.Symbols.NumSymbols())
+	for _, r := range g.Rules {
+		if r != g.AcceptRule && gen.ruleHeight(r) != math.MaxInt {
+			gen.rules[r.LHS.ID] = append(gen.rules[r.LHS.ID], r)
+		}
+	}
+	gen.reach()
+	return gen, nil
+}
+
+// heights computes the minimum derivation tree height of every symbol by
+// fixed-point iteration. Heights only decrease, so the loop terminates.
+// The error pseudo-token generates nothing, so rules that need it are never
+// used.
+func heights(g *grammar.Grammar) []int {
+	h := make([]int, g.Symbols.NumSymbols())
+	for _, s := range g.Symbols.All() {
+		if s.Kind == grammar.SymbolNonterminal || s.Name == grammar.ErrorName {
+			h[s.ID] = math.MaxInt
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range g.Rules {
+			rh := 1
+			for _, s := range r.RHS {
+				if h[s.ID] == math.MaxInt {
+					rh = math.MaxInt
+					break
+				}
+				rh = max(rh, h[s.ID]+1)
+			}
+			if rh < h[r.LHS.ID] {
+				h[r.LHS.ID] = rh
+				changed = true
+			}
+		}
+	}
+	return h
+}
+
+// ruleHeight returns the minimum height of a derivation tree rooted at r.
+func (gen *Generator) ruleHeight(r *grammar.Rule) int {
+	rh := 1
+	for _, s := range r.RHS {
+		if gen.height[s.ID] == math.MaxInt {
+			return math.MaxInt
+		}
+		rh = max(rh, gen.height[s.ID]+1)
+	}
+	return rh
+}
+
+// Sentence returns one random sentence derived from the start symbol.
+func (gen *Generator) Sentence() []*grammar.Symbol {
+	return gen.expand(gen.g.Start, 0, nil)
+}
+
+// expand appends a random derivation of sym to out.
+func (gen *Generator) expand(sym *grammar.Symbol, depth int, out []*grammar.Symbol) []*grammar.Symbol {
+	if sym.Kind == grammar.SymbolTerminal {
+		return append(out, sym)
+	}
+	r := gen.choose(sym, depth)
+	for _, s := range r.RHS {
+		out = gen.expand(s, depth+1, out)
+	}
+	return out
+}
+
+// choose picks a rule for nt. Below MaxDepth the choice is weighted random
+// over all productive rules. At or beyond MaxDepth it is uniform over the
+// rules whose height equals the height of nt, which strictly decreases the
+// height of every open nonterminal and so guarantees termination.
+func (gen *Generator) choose(nt *grammar.Symbol, depth int) *grammar.Rule {
+	rules := gen.rules[nt.ID]
+	if depth < gen.opts.MaxDepth {
+		total := 0.0
+		for _, r := range rules {
+			total += gen.weight(r)
+		}
+		if total > 0 {
+			x := gen.rng.Float64() * total
+			for _, r := range rules {
+				if x -= gen.weight(r); x < 0 {
+					return r
+				}
+			}
+		}
+	}
+	var shallow []*grammar.Rule
+	for _, r := range rules {
+		if gen.ruleHeight(r) == gen.height[nt.ID] {
+			shallow = append(shallow, r)
+		}
+	}
+	return shallow[gen.rng.IntN(len(shallow))]
+}
+
+func (gen *Generator) weight(r *grammar.Rule) float64 {
+	if w, ok := gen.opts.Weights[r.Index]; ok {
+		return w
+	}
+	return 1
+}
+
+// Cover returns, for every rule reachable from the start symbol, the
+// sentence returned by Covering, in rule order.
+func (gen *Generator) Cover() [][]*grammar.Symbol {
+	var sentences [][]*grammar.Symbol
+	for _, r := range gen.g.Rules {
+		if s, ok := gen.Covering(r); ok {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// Covering returns a short sentence whose derivation uses rule r, or false
+// if r is unreachable or unproductive. The derivation follows a shortest
+// path of rules from the start symbol to the rule's LHS and finishes every
+// other nonterminal with its first shallowest rule, so the result does not
+// depend on the random source.
+func (gen *Generator) Covering(r *grammar.Rule) ([]*grammar.Symbol, bool) {
+	g := gen.g
+	if _, ok := gen.via[r.LHS]; (!ok && r.LHS != g.Start) || r == g.AcceptRule || gen.ruleHeight(r) == math.MaxInt {
+		return nil, false
+	}
+	var chain []step
+	for nt := r.LHS; nt != g.Start; nt = gen.via[nt].rule.LHS {
+		chain = append([]step{gen.via[nt]}, chain...)
+	}
+	var build func(i int, out []*grammar.Symbol) []*grammar.Symbol
+	build = func(i int, out []*grammar.Symbol) []*grammar.Symbol {
+		if i == len(chain) {
+			for _, s := range r.RHS {
+				out = gen.shallowest(s, out)
+			}
+			return out
+		}
+		for j, s := range chain[i].rule.RHS {
+			if j == chain[i].pos {
+				out = build(i+1, out)
+			} else {
+				out = gen.shallowest(s, out)
+			}
+		}
+		return out
+	}
+	return build(0, nil), true
+}
+
+// step records that a nonterminal is reached through position pos of rule.
+type step struct {
+	rule *grammar.Rule
+	pos  int
+}
+
+// reach does a breadth-first search over the nonterminals from the start
+// symbol and records in via how each was first reached.
+func (gen *Generator) reach() {
+	g := gen.g
+	gen.via = map[*grammar.Symbol]step{}
+	seen := map[*grammar.Symbol]bool{g.Start: true}
+	queue := []*grammar.Symbol{g.Start}
+	for len(queue) > 0 {
+		nt := queue[0]
+		queue = queue[1:]
+		for _, r := range gen.rules[nt.ID] {
+			for pos, s := range r.RHS {
+				if s.Kind == grammar.SymbolNonterminal && !seen[s] {
+					seen[s] = true
+					gen.via[s] = step{rule: r, pos: pos}
+					queue = append(queue, s)
+				}
+			}
+		}
+	}
+}
+
+// shallowest appends the derivation of sym that always takes the first
+// rule of minimum height.
+func (gen *Generator) shallowest(sym *grammar.Symbol, out []*grammar.Symbol) []*grammar.Symbol {
+	if sym.Kind == grammar.SymbolTerminal {
+		return append(out, sym)
+	}
+	for _, r := range gen.rules[sym.ID] {
+		if gen.ruleHeight(r) == gen.height[sym.ID] {
+			for _, s := range r.RHS {
+				out = gen.shallowest(s, out)
+			}
+			break
+		}
+	}
+	return out
+}
+
+// Format returns the sentence as space-separated terminal names.
+func Format(sentence []*grammar.Symbol) string {
+	names := make([]string, len(sentence))
+	for i, s := range sentence {
+		names[i] = s.Name
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package sentence
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func mustGrammar(t *testing.T, src string) *grammar.Grammar {
+	t.Helper()
+	tokens, err := lex.Tokenize("test.y", []byte(src))
+	if err != nil {
+		t.Fatalf("Tokenize: %v", err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if diags, err := g.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v %v", err, diags)
+	}
+	return g
+}
+
+func TestEnumerate(t *testing.T) {
+	g := mustGrammar(t, `
+expr ::= expr PLUS NUM.
+expr ::= NUM.
+`)
+	got, err := Enumerate(g, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"NUM", "NUM PLUS NUM"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if Format(got[i]) != want[i] {
+			t.Errorf("sentence %d = %q, want %q", i, Format(got[i]), want[i])
+		}
+	}
+}
+
+func TestEnumerateEpsilonCycle(t *testing.T) {
+	g := mustGrammar(t, `
+list ::= list item.
+list ::= .
+list ::= list.
+item ::= WORD.
+`)
+	got, err := Enumerate(g, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "WORD", "WORD WORD"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if Format(got[i]) != want[i] {
+			t.Errorf("sentence %d = %q, want %q", i, Format(got[i]), want[i])
+		}
+	}
+}
+
+func TestRandomSentencesAreBalanced(t *testing.T) {
+	g := mustGrammar(t, `
+s ::= LP s RP s.
+s ::= .
+`)
+	gen, err := New(g, rand.New(rand.NewPCG(1, 2)), Options{MaxDepth: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		depth := 0
+		for _, sym := range gen.Sentence() {
+			switch sym.Name {
+			case "LP":
+				depth++
+			case "RP":
+				depth--
+			}
+			if depth < 0 {
+				t.Fatalf("unbalanced sentence")
+			}
+		}
+		if depth != 0 {
+			t.Fatalf("unbalanced sentence")
+		}
+	}
+}
+
+func TestWeightZeroDisablesRule(t *testing.T) {
+	g := mustGrammar(t, `
+expr ::= expr PLUS NUM.
+expr ::= NUM.
+`)
+	gen, err := New(g, rand.New(rand.NewPCG(1, 2)), Options{Weights: map[int]float64{0: 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if s := Format(gen.Sentence()); s != "NUM" {
+			t.Fatalf("sentence = %q, want %q", s, "NUM")
+		}
+	}
+}
+
+func TestCover(t *testing.T) {
+	g := mustGrammar(t, `
+program ::= stmt_list.
+stmt_list ::= stmt_list stmt.
+stmt_list ::= .
+stmt ::= PRINT expr SEMI.
+stmt ::= expr SEMI.
+expr ::= expr PLUS NUM.
+expr ::= NUM.
+`)
+	gen, err := New(g, rand.New(rand.NewPCG(1, 2)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"",
+		"PRINT NUM SEMI",
+		"",
+		"PRINT NUM SEMI",
+		"NUM SEMI",
+		"PRINT NUM PLUS NUM SEMI",
+		"PRINT NUM SEMI",
+	}
+	got := gen.Cover()
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if Format(got[i]) != want[i] {
+			t.Errorf("cover %d = %q, want %q", i, Format(got[i]), want[i])
+		}
+	}
+}
+
+func TestErrorSymbolIsNotGenerated(t *testing.T) {
+	g := mustGrammar(t, `
+program ::= stmt_list.
+stmt_list ::= stmt_list stmt.
+stmt_list ::= .
+stmt ::= error SEMI.
+stmt ::= NUM SEMI.
+`)
+	gen, err := New(g, rand.New(rand.NewPCG(1, 2)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "NUM SEMI", "", "NUM SEMI"}
+	got := gen.Cover()
+	if len(got) != len(want) {
+		t.Fatalf("got %d sentences, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if Format(got[i]) != want[i] {
+			t.Errorf("cover %d = %q, want %q", i, Format(got[i]), want[i])
+		}
+	}
+	for i := 0; i < 50; i++ {
+		for _, sym := range gen.Sentence() {
+			if sym.Name == grammar.ErrorName {
+				t.Fatalf("random sentence contains %s", grammar.ErrorName)
+			}
+		}
+	}
+	all, err := Enumerate(g, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"", "NUM SEMI", "NUM SEMI NUM SEMI"}
+	if len(all) != len(want) {
+		t.Fatalf("got %d sentences, want %d", len(all), len(want))
+	}
+	for i := range want {
+		if Format(all[i]) != want[i] {
+			t.Errorf("sentence %d = %q, want %q", i, Format(all[i]), want[i])
+		}
+	}
+}
+
+func TestNewRejectsUnproductiveStart(t *testing.T) {
+	tokens, _ := lex.Tokenize("test.y", []byte("loop ::= loop X."))
+	g, _, _ := grammar.ParseGrammar(tokens)
+	g.Finalize()
+	if _, err := New(g, rand.New(rand.NewPCG(1, 2)), Options{}); err == nil {
+		t.Error("expected error for unproductive start symbol")
+	}
+}
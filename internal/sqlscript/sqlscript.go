@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package sqlscript describes a grammar and its parser as a SQL script for
+// the sqlite3 command-line shell, in the spirit of Lemon's -S option:
+//
+//	sqlite3 calc.db < calc.sql
+//
+// The script drops and recreates its tables, so loading it again replaces
+// the previous description. To follow a grammar across commits, load each
+// version into its own database and ATTACH them side by side.
+package sqlscript
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mdhender/guanabana/internal/lalr"
+)
+
+// schema creates the tables. Symbols, rules and states are keyed by the
+// IDs the generator uses; an action's target is a state for a shift or
+// goto and a rule for a reduce.
+const schema = `BEGIN TRANSACTION;
+DROP TABLE IF EXISTS conflict;
+DROP TABLE IF EXISTS action;
+DROP TABLE IF EXISTS item;
+DROP TABLE IF EXISTS state;
+DROP TABLE IF EXISTS rulerhs;
+DROP TABLE IF EXISTS rule;
+DROP TABLE IF EXISTS symbol;
+DROP TABLE IF EXISTS grammar;
+CREATE TABLE grammar(
+  file TEXT NOT NULL,
+  mode TEXT NOT NULL,
+  terminals INTEGER NOT NULL,
+  nonterminals INTEGER NOT NULL,
+  rules INTEGER NOT NULL,
+  states INTEGER NOT NULL,
+  conflicts INTEGER NOT NULL
+);
+CREATE TABLE symbol(
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  is_terminal BOOLEAN NOT NULL,
+  alias TEXT,
+  precedence INTEGER,
+  assoc TEXT
+);
+CREATE TABLE rule(
+  id INTEGER PRIMARY KEY,
+  lhs INTEGER NOT NULL REFERENCES symbol(id),
+  txt TEXT NOT NULL,
+  line INTEGER,
+  precedence INTEGER
+);
+CREATE TABLE rulerhs(
+  rule INTEGER NOT NULL REFERENCES rule(id),
+  pos INTEGER NOT NULL,
+  sym INTEGER NOT NULL REFERENCES symbol(id),
+  PRIMARY KEY(rule, pos)
+);
+CREATE TABLE state(
+  id INTEGER PRIMARY KEY,
+  sym INTEGER REFERENCES symbol(id)
+);
+CREATE TABLE item(
+  state INTEGER NOT NULL REFERENCES state(id),
+  rule INTEGER NOT NULL REFERENCES rule(id),
+  dot INTEGER NOT NULL,
+  is_kernel BOOLEAN NOT NULL
+);
+CREATE TABLE action(
+  state INTEGER NOT NULL REFERENCES state(id),
+  sym INTEGER NOT NULL REFERENCES symbol(id),
+  kind TEXT NOT NULL,
+  target INTEGER,
+  PRIMARY KEY(state, sym)
+);
+CREATE TABLE conflict(
+  state INTEGER NOT NULL REFERENCES state(id),
+  sym INTEGER NOT NULL REFERENCES symbol(id),
+  kind TEXT NOT NULL,
+  rule1 INTEGER NOT NULL REFERENCES rule(id),
+  rule2 INTEGER REFERENCES rule(id),
+  resolution TEXT NOT NULL,
+  resolved BOOLEAN NOT NULL
+);
+`
+
+// null is the SQL NULL value.
+const null = "NULL"
+
+// Write writes the script for an automaton with lookaheads.
+func Write(w io.Writer, grammarFile string, a *lalr.Automaton) error {
+	g := a.Grammar
+	conflicts := lalr.DetectAndResolveConflicts(a, g)
+	table := lalr.BuildTables(a, g)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(schema)
+	insert(bw, "grammar", quote(grammarFile), quote(a.Mode.String()), num(table.NumTerminals), num(table.NumNonterminals),
+		num(len(g.Rules)), num(table.NumStates), num(conflicts.Unresolved))
+	for _, sym := range g.Symbols.All() {
+		prec, assoc := null, null
+		if !sym.Precedence.IsZero() {
+			prec, assoc = num(sym.Precedence.Level), quote(sym.Precedence.Assoc.String())
+		}
+		alias := null
+		if sym.Alias != "" {
+			alias = quote(sym.Alias)
+		}
+		insert(bw, "symbol", num(sym.ID), quote(sym.Name), boolean(sym.IsTerminal()), alias, prec, assoc)
+	}
+	for _, r := range g.Rules {
+		line, prec := null, null
+		if r.Pos.Line > 0 {
+			line = num(r.Pos.Line)
+		}
+		if !r.Precedence.IsZero() {
+			prec = num(r.Precedence.Level)
+		}
+		insert(bw, "rule", num(r.Index), num(r.LHS.ID), quote(r.String()), line, prec)
+		for pos, sym := range r.RHS {
+			insert(bw, "rulerhs", num(r.Index), num(pos), num(sym.ID))
+		}
+	}
+	for _, s := range a.States {
+		sym := null
+		if s.Symbol != nil {
+			sym = num(s.Symbol.ID)
+		}
+		insert(bw, "state", num(s.ID), sym)
+		for _, item := range s.Items.Items {
+			insert(bw, "item", num(s.ID), num(item.RuleIndex), num(item.Dot), boolean(item.IsKernel(g)))
+		}
+		for term, act := range table.Action[s.ID] {
+			switch act.Kind {
+			case lalr.ActionShift:
+				insert(bw, "action", num(s.ID), num(term), quote("shift"), num(act.State))
+			case lalr.ActionReduce:
+				insert(bw, "action", num(s.ID), num(term), quote("reduce"), num(act.RuleIndex))
+			case lalr.ActionAccept:
+				insert(bw, "action", num(s.ID), num(term), quote("accept"), null)
+			}
+		}
+		for _, nt := range g.Symbols.Nonterminals() {
+			if to := table.GotoState(s.ID, nt); to >= 0 {
+				insert(bw, "action", num(s.ID), num(nt.ID), quote("goto"), num(to))
+			}
+		}
+	}
+	for _, c := range conflicts.Conflicts {
+		rule2 := null
+		if c.ReduceItem2 != nil {
+			rule2 = num(c.ReduceItem2.RuleIndex)
+		}
+		insert(bw, "conflict", num(c.StateID), num(c.Terminal.ID), quote(c.Kind.String()), num(c.ReduceItem.RuleIndex), rule2,
+			quote(c.Resolution), boolean(c.Resolved))
+	}
+	bw.WriteString("COMMIT;\n")
+	return bw.Flush()
+}
+
+// quote makes a SQL string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func num(n int) string {
+	return strconv.Itoa(n)
+}
+
+// boolean writes a truth value the way SQLite stores it.
+func boolean(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// insert writes one INSERT statement of SQL values.
+func insert(w *bufio.Writer, table string, values ...string) {
+	fmt.Fprintf(w, "INSERT INTO %s VALUES(%s);\n", table, strings.Join(values, ","))
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package sqlscript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdhender/guanabana/internal/grammar"
+	"github.com/mdhender/guanabana/internal/lalr"
+	"github.com/mdhender/guanabana/internal/lex"
+)
+
+func TestWrite(t *testing.T) {
+	src := `
+%token QUOTE "'".
+%left PLUS.
+stmt ::= IF expr THEN stmt.
+stmt ::= IF expr THEN stmt ELSE stmt.
+stmt ::= QUOTE.
+expr ::= expr PLUS expr.
+expr ::= ID.
+`
+	tokens, err := lex.Tokenize("it's.y", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, diags, err := grammar.ParseGrammar(tokens)
+	if err != nil || grammar.HasErrors(diags) {
+		t.Fatalf("ParseGrammar: %v %v", err, diags)
+	}
+	if _, err := g.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	a := lalr.BuildLALR(g)
+	var sb strings.Builder
+	if err := Write(&sb, "it's.y", a); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	plus, _ := g.Symbols.Lookup("PLUS")
+	quote, _ := g.Symbols.Lookup("QUOTE")
+	for _, want := range []string{
+		"BEGIN TRANSACTION;\n",
+		"CREATE TABLE symbol(",
+		"INSERT INTO grammar VALUES('it''s.y','lalr',7,3,6,12,1);\n",
+		"INSERT INTO symbol VALUES(0,'$',1,NULL,NULL,NULL);\n",
+		"INSERT INTO symbol VALUES(" + num(plus.ID) + ",'PLUS',1,NULL,1,'left');\n",
+		"INSERT INTO symbol VALUES(" + num(quote.ID) + ",'QUOTE',1,'''',NULL,NULL);\n",
+		"INSERT INTO rule VALUES(0,",
+		",'stmt ::= IF expr THEN stmt.',4,NULL);\n",
+		"INSERT INTO rulerhs VALUES(0,0,",
+		"INSERT INTO state VALUES(0,NULL);\n",
+		"INSERT INTO item VALUES(0,",
+		",'accept',NULL);\n",
+		",'goto',",
+		",'shift/reduce',0,NULL,'shift',0);\n",
+		",'shift/reduce',3,NULL,'reduce',1);\n",
+		"COMMIT;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("script is missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "INSERT INTO state "); n != len(a.States) {
+		t.Errorf("%d state rows, want %d", n, len(a.States))
+	}
+}